@@ -17,6 +17,7 @@ limitations under the License.
 package v1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -31,30 +32,76 @@ type DatabaseSpec struct {
 	SnapshotID       string `json:"snapshotId,omitempty"`
 }
 type FsxSpec struct {
+	// SnapshotId is restored when set, otherwise a new volume is provisioned through FsxStorageClassName
 	SnapshotId                 string `json:"snapshotId,omitempty"`
+	FsxStorageClassName        string `json:"storageClassName,omitempty"`
 	FsxRestoreStorageClassName string `json:"restoreStorageClassName,omitempty"`
 	FsxVolumeSnapshotClassName string `json:"volumeSnapshotClassName,omitempty"`
 
 	FsxCsiDriverName string `json:"csiDriverName,omitempty"`
 }
 
+// EfsAccessPointSpec configures a per-instance EFS access point, which allows
+// several Jira instances to share one filesystem without seeing each other's data
+type EfsAccessPointSpec struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// RootDirectory defaults to /<jira name>-<jira uid>
+	RootDirectory string `json:"rootDirectory,omitempty"`
+	// Uid and Gid should match the user Jira container runs as
+	Uid         int64  `json:"uid,omitempty"`
+	Gid         int64  `json:"gid,omitempty"`
+	Permissions string `json:"permissions,omitempty"`
+}
+
 type EfsSpec struct {
 	EfsStorageClassName string `json:"storageClassName,omitempty"`
 	EfsCsiDriverName    string `json:"csiDriverName,omitempty"`
+	// FileSystemId is an existing filesystem shared by several Jira instances.
+	// When set, the operator does not create a filesystem and mount targets, and always uses an access point
+	FileSystemId string             `json:"fileSystemId,omitempty"`
+	AccessPoint  EfsAccessPointSpec `json:"accessPoint,omitempty"`
 }
 
 type EbsSpec struct {
 	EbsStorageClassName string `json:"storageClassName,omitempty"`
 	EbsFsType           string `json:"fsType,omitempty"`
-	SnapshotId          string `json:"snapshotId,omitempty"`
-	AvailabilityZone    string `json:"availabilityZone,omitempty"`
+	// SnapshotId is restored when set, otherwise an empty volume is created and initialized for Jira
+	SnapshotId       string `json:"snapshotId,omitempty"`
+	AvailabilityZone string `json:"availabilityZone,omitempty"`
+	// OwnerUid and OwnerGid own the root of an empty volume, and should match the user Jira container runs as
+	OwnerUid int64 `json:"ownerUid,omitempty"`
+	OwnerGid int64 `json:"ownerGid,omitempty"`
+}
+
+const (
+	SharedFSTypeEfs = "efs"
+	SharedFSTypeEbs = "ebs"
+	SharedFSTypeFsx = "fsx"
+)
+
+// NfsServerSpec configures in-cluster nfs server which exports EBS volume as shared home
+type NfsServerSpec struct {
+	Image             string                      `json:"image,omitempty"`
+	Resources         corev1.ResourceRequirements `json:"resources,omitempty"`
+	PriorityClassName string                      `json:"priorityClassName,omitempty"`
+	// TerminationGracePeriodSeconds defaults to 30, 0 kills nfs server immediately
+	TerminationGracePeriodSeconds *int64 `json:"terminationGracePeriodSeconds,omitempty"`
+	// PodDisruptionBudget prevents voluntary evictions from taking shared home down. As nfs server has a single replica,
+	// node drains are blocked until its pod is moved by hand, so it is off by default
+	PodDisruptionBudget bool `json:"podDisruptionBudget,omitempty"`
+	// JiraPodAntiAffinity prefers scheduling nfs server away from Jira pods, so that a node failure does not take down both
+	JiraPodAntiAffinity bool `json:"jiraPodAntiAffinity,omitempty"`
 }
 
 type SharedFS struct {
+	// Type is one of efs, ebs or fsx. When empty, it is ebs or fsx if a snapshot of that type is set, and efs otherwise
+	Type       string  `json:"type,omitempty"`
 	VolumeSize int64   `json:"volumeSize,omitempty"`
 	Ebs        EbsSpec `json:"ebs,omitempty"`
 	Efs        EfsSpec `json:"efs,omitempty"`
 	Fsx        FsxSpec `json:"fsx,omitempty"`
+	// NfsServer is applicable only to EBS volumes
+	NfsServer NfsServerSpec `json:"nfsServer,omitempty"`
 }
 
 type HelmValues struct {
@@ -74,13 +121,121 @@ type SyncPolicy struct {
 	ApplyOutOfSyncOnly bool `json:"applyOutOfSyncOnly,omitempty"`
 }
 
+// ArgoCDDestination is a cluster registered in Argo CD. Either Name or Server may be set, and when neither is,
+// Jira is deployed to the cluster the operator runs on
+type ArgoCDDestination struct {
+	Name   string `json:"name,omitempty"`
+	Server string `json:"server,omitempty"`
+}
+
+// IsLocal returns whether Jira is deployed to the cluster the operator runs on
+func (d ArgoCDDestination) IsLocal() bool {
+	return (d.Name == "" || d.Name == "in-cluster") && (d.Server == "" || d.Server == InClusterServer)
+}
+
+const (
+	InClusterServer = "https://kubernetes.default.svc"
+
+	ArgoCDKindApplication    = "Application"
+	ArgoCDKindApplicationSet = "ApplicationSet"
+)
+
+const (
+	DeliveryTypeArgoCD = "argocd"
+	DeliveryTypeFlux   = "flux"
+	DeliveryTypeHelm   = "helm"
+)
+
+// DeliverySpec selects the backend deploying Jira Helm chart. All backends read chart and value overrides from ArgoCDSpec
+type DeliverySpec struct {
+	// Type is one of argocd, flux or helm, which installs the chart from the operator without any GitOps tool
+	Type string   `json:"type,omitempty"`
+	Flux FluxSpec `json:"flux,omitempty"`
+}
+
+type FluxSpec struct {
+	// Interval at which Flux reconciles the HelmRelease
+	Interval string `json:"interval,omitempty"`
+}
+
+// PromotionSpec configures rollouts of a new chart version or values revision
+type PromotionSpec struct {
+	// Enabled snapshots database and shared home before a rollout, and rolls back to the previous chart version and values
+	// revision when Jira is not healthy within HealthTimeout
+	Enabled bool `json:"enabled,omitempty"`
+	// SkipSnapshots rolls out without snapshots, e.g. for test instances whose data can be lost
+	SkipSnapshots bool `json:"skipSnapshots,omitempty"`
+	// HealthTimeout is how long a rollout may take to become healthy, 30m by default
+	HealthTimeout string `json:"healthTimeout,omitempty"`
+}
+
+// BlueGreenSpec configures upgrades deploying the new chart version to a green stack, restored from snapshots of the live one
+type BlueGreenSpec struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// RollbackWindow is how long the previous stack is kept after DNS switched to the green one, 24h by default
+	RollbackWindow string `json:"rollbackWindow,omitempty"`
+}
+
+const (
+	DNSProviderExternalDNS = "external-dns"
+	DNSProviderRoute53     = "route53"
+)
+
+// DNSSpec configures the record of Jira hostname
+type DNSSpec struct {
+	// Provider is external-dns, which creates the record from ingress annotations, or route53, for which the operator
+	// creates an alias record of the ingress load balancer in HostedZoneId with Crossplane
+	Provider     string `json:"provider,omitempty"`
+	HostedZoneId string `json:"hostedZoneId,omitempty"`
+	// LoadBalancerHostedZoneId is the hosted zone of ALBs in the region of Jira, which is known for most regions
+	LoadBalancerHostedZoneId string `json:"loadBalancerHostedZoneId,omitempty"`
+}
+
 type ArgoCDSpec struct {
-	HelmValues     HelmValues `json:"helmValues,omitempty"`
-	HelmChart      HelmChart  `json:"helmChart,omitempty"`
-	Namespace      string     `json:"namespace,omitempty"`
-	Project        string     `json:"project,omitempty"`
-	SyncPolicy     SyncPolicy `json:"syncPolicy,omitempty"`
-	RetainOnDelete bool       `json:"retainOnDelete,omitempty"`
+	// Kind of Argo CD resource Jira is deployed with, either ApplicationSet or Application
+	Kind           string            `json:"kind,omitempty"`
+	Destination    ArgoCDDestination `json:"destination,omitempty"`
+	HelmValues     HelmValues        `json:"helmValues,omitempty"`
+	HelmChart      HelmChart         `json:"helmChart,omitempty"`
+	Namespace      string            `json:"namespace,omitempty"`
+	Project        string            `json:"project,omitempty"`
+	SyncPolicy     SyncPolicy        `json:"syncPolicy,omitempty"`
+	RetainOnDelete bool              `json:"retainOnDelete,omitempty"`
+}
+
+// GetType returns the shared filesystem type, falling back to the type of the configured snapshot
+func (s SharedFS) GetType() string {
+	if s.Type != "" {
+		return s.Type
+	}
+	if s.Ebs.SnapshotId != "" {
+		return SharedFSTypeEbs
+	}
+	if s.Fsx.SnapshotId != "" {
+		return SharedFSTypeFsx
+	}
+	return SharedFSTypeEfs
+}
+
+const (
+	// NamespaceModeCreate creates the target namespace and deletes it together with Jira
+	NamespaceModeCreate = "Create"
+	// NamespaceModeAdopt uses an existing namespace, which is never deleted by the operator
+	NamespaceModeAdopt = "Adopt"
+)
+
+// TargetNamespaceSpec configures the namespace Jira and its dependencies are deployed to
+type TargetNamespaceSpec struct {
+	// Name defaults to Jira name
+	Name string `json:"name,omitempty"`
+	// Mode is Create or Adopt. There is no mode deploying to the namespace of the Jira custom resource, as Jira is
+	// cluster scoped and has none; Adopt with the name of a namespace a team owns serves that purpose
+	Mode        string            `json:"mode,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// ResourceQuota and LimitRange are created in the target namespace when set
+	ResourceQuota *corev1.ResourceQuotaSpec `json:"resourceQuota,omitempty"`
+	LimitRange    *corev1.LimitRangeSpec    `json:"limitRange,omitempty"`
 }
 
 type Network struct {
@@ -88,22 +243,62 @@ type Network struct {
 	SecurityGroupIds []string `json:"securityGroupIds,omitempty"`
 }
 
+// JvmSpec configures Jira JVM
+type JvmSpec struct {
+	MinHeap        string   `json:"minHeap,omitempty"`
+	MaxHeap        string   `json:"maxHeap,omitempty"`
+	AdditionalArgs []string `json:"additionalArgs,omitempty"`
+}
+
+// JiraAppSpec configures Jira application deployed by Argo CD. Fields which are not set keep the values from Helm values files
+type JiraAppSpec struct {
+	Replicas *int32 `json:"replicas,omitempty"`
+	// Version is the tag of Jira image
+	Version   string                      `json:"version,omitempty"`
+	Jvm       JvmSpec                     `json:"jvm,omitempty"`
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
 // JiraSpec defines the desired state of Jira
 type JiraSpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 
 	// Foo is an example field of Jira. Edit jira_types.go to remove/update
-	AWSRegion                 string       `json:"awsRegion,omitempty"`
-	RetainOnDelete            bool         `json:"retainOnDelete,omitempty"`
-	Database                  DatabaseSpec `json:"database,omitempty"`
-	Hostname                  string       `json:"hostname,omitempty"`
-	CrossplaneAwsProviderName string       `json:"crossplaneAwsProviderName,omitempty"`
-	ArgoCD                    ArgoCDSpec   `json:"argocd,omitempty"`
-	SharedFS                  SharedFS     `json:"sharedFs,omitempty"`
-	Network                   Network      `json:"network,omitempty"`
-	KMSKeyId                  string       `json:"kmsKeyId,omitempty"`
-	RdsRoleArn                string       `json:"rdsRoleArn,omitempty"`
+	AWSRegion                 string              `json:"awsRegion,omitempty"`
+	RetainOnDelete            bool                `json:"retainOnDelete,omitempty"`
+	Database                  DatabaseSpec        `json:"database,omitempty"`
+	Hostname                  string              `json:"hostname,omitempty"`
+	CrossplaneAwsProviderName string              `json:"crossplaneAwsProviderName,omitempty"`
+	ArgoCD                    ArgoCDSpec          `json:"argocd,omitempty"`
+	Delivery                  DeliverySpec        `json:"delivery,omitempty"`
+	Promotion                 PromotionSpec       `json:"promotion,omitempty"`
+	BlueGreen                 BlueGreenSpec       `json:"blueGreen,omitempty"`
+	DNS                       DNSSpec             `json:"dns,omitempty"`
+	SharedFS                  SharedFS            `json:"sharedFs,omitempty"`
+	Network                   Network             `json:"network,omitempty"`
+	KMSKeyId                  string              `json:"kmsKeyId,omitempty"`
+	RdsRoleArn                string              `json:"rdsRoleArn,omitempty"`
+	TargetNamespace           TargetNamespaceSpec `json:"targetNamespace,omitempty"`
+	// Paused stops reconciliation of Jira and all its resources, same as PausedAnnotation
+	Paused bool `json:"paused,omitempty"`
+	// Maintenance scales Jira down to zero replicas while its infrastructure is still reconciled
+	Maintenance bool `json:"maintenance,omitempty"`
+	// Product is the data center product deployed, one of jira, jsm, confluence or bitbucket
+	Product string      `json:"product,omitempty"`
+	Jira    JiraAppSpec `json:"jira,omitempty"`
+}
+
+const (
+	ProductJira       = "jira"
+	ProductJSM        = "jsm"
+	ProductConfluence = "confluence"
+	ProductBitbucket  = "bitbucket"
+)
+
+// IsPaused returns true when reconciliation is paused through the spec or PausedAnnotation
+func (j *Jira) IsPaused() bool {
+	return j.Spec.Paused || j.Annotations[PausedAnnotation] == "true"
 }
 
 type RDSStatus struct {
@@ -116,19 +311,177 @@ type RDSStatus struct {
 type AppStatus struct {
 	Health string `json:"health,omitempty"`
 	Sync   string `json:"sync,omitempty"`
+	// Revisions are the revisions synced from the chart and values sources, in the order of sources
+	Revisions []string `json:"revisions,omitempty"`
+	// OperationPhase and OperationMessage describe the last sync operation
+	OperationPhase   string       `json:"operationPhase,omitempty"`
+	OperationMessage string       `json:"operationMessage,omitempty"`
+	LastSyncedAt     *metav1.Time `json:"lastSyncedAt,omitempty"`
+	// Conditions are errors and warnings of the application, such as ComparisonError or SyncError
+	Conditions []AppCondition `json:"conditions,omitempty"`
+	// DegradedResources are resources of the application which are degraded or missing, as kind/namespace/name
+	DegradedResources []string `json:"degradedResources,omitempty"`
+	// Version is the image tag Jira StatefulSet runs
+	Version       string `json:"version,omitempty"`
+	Replicas      int32  `json:"replicas,omitempty"`
+	ReadyReplicas int32  `json:"readyReplicas,omitempty"`
+}
+
+type AppCondition struct {
+	Type    string `json:"type"`
+	Message string `json:"message,omitempty"`
+}
+
+// Release is a chart version and values revision Jira is deployed with
+type Release struct {
+	ChartVersion string `json:"chartVersion,omitempty"`
+	GitRevision  string `json:"gitRevision,omitempty"`
+}
+
+func (r Release) String() string {
+	return "chart " + r.ChartVersion + " with values revision " + r.GitRevision
+}
+
+const (
+	RolloutPhaseSnapshotting = "Snapshotting"
+	RolloutPhaseRollingOut   = "RollingOut"
+	RolloutPhaseSucceeded    = "Succeeded"
+	RolloutPhaseRolledBack   = "RolledBack"
+	RolloutPhaseFailed       = "Failed"
+)
+
+// Rollout is the promotion of Jira from one release to another
+type Rollout struct {
+	From  Release `json:"from,omitempty"`
+	To    Release `json:"to"`
+	Phase string  `json:"phase"`
+	// DatabaseSnapshotId and SharedHomeSnapshotId are taken before the rollout, shared home on EFS is not snapshotted
+	DatabaseSnapshotId   string       `json:"databaseSnapshotId,omitempty"`
+	SharedHomeSnapshotId string       `json:"sharedHomeSnapshotId,omitempty"`
+	StartedAt            *metav1.Time `json:"startedAt,omitempty"`
+	CompletedAt          *metav1.Time `json:"completedAt,omitempty"`
+	Message              string       `json:"message,omitempty"`
+}
+
+type PromotionStatus struct {
+	// Release is the chart version and values revision deployed, which differs from spec after a rollback
+	Release Release `json:"release,omitempty"`
+	// Rollout is the latest rollout, which may still be in progress
+	Rollout *Rollout `json:"rollout,omitempty"`
+	// History of finished rollouts, most recent first
+	History []Rollout `json:"history,omitempty"`
+}
+
+const (
+	BlueGreenPhaseSnapshotting = "Snapshotting"
+	BlueGreenPhaseDeploying    = "Deploying"
+	BlueGreenPhaseSwitched     = "Switched"
+	BlueGreenPhaseCompleted    = "Completed"
+	BlueGreenPhaseRolledBack   = "RolledBack"
+	BlueGreenPhaseAborted      = "Aborted"
+)
+
+// BlueGreenStatus tracks the stacks of a Jira upgraded with blue/green deployment. Stacks other than the one
+// of the Jira itself are Jiras owned by it
+type BlueGreenStatus struct {
+	// Live is the name of the Jira serving the hostname, this Jira when empty
+	Live        string  `json:"live,omitempty"`
+	LiveRelease Release `json:"liveRelease,omitempty"`
+	Phase       string  `json:"phase,omitempty"`
+	// Green is the stack being deployed, and Previous the stack replaced by it, kept during the rollback window
+	Green    string  `json:"green,omitempty"`
+	Previous string  `json:"previous,omitempty"`
+	From     Release `json:"from,omitempty"`
+	To       Release `json:"to,omitempty"`
+	// DatabaseSnapshotId and SharedHomeSnapshotId are taken from the live stack, and restored in the green one
+	DatabaseSnapshotId   string       `json:"databaseSnapshotId,omitempty"`
+	SharedHomeSnapshotId string       `json:"sharedHomeSnapshotId,omitempty"`
+	StartedAt            *metav1.Time `json:"startedAt,omitempty"`
+	SwitchedAt           *metav1.Time `json:"switchedAt,omitempty"`
+	Message              string       `json:"message,omitempty"`
+	// Standby is true once the stack of this Jira is scaled down, after DNS switched to another stack
+	Standby bool `json:"standby,omitempty"`
+	// Frozen is true while the live stack is put in maintenance by blue/green deployment, until its snapshots are taken
+	Frozen bool `json:"frozen,omitempty"`
+}
+
+type DNSStatus struct {
+	// LoadBalancer is the hostname of the load balancer of Jira ingress, which the record is an alias of
+	LoadBalancer string `json:"loadBalancer,omitempty"`
+	// Addresses Jira hostname resolves to from the operator
+	Addresses []string `json:"addresses,omitempty"`
 }
 
 type SharedFilesystemStatus struct {
-	EfsId string `json:"efsId,omitempty"`
-	EbsId string `json:"ebsId,omitempty"`
-	FsxId string `json:"fsxId,omitempty"`
+	EfsId            string `json:"efsId,omitempty"`
+	EfsAccessPointId string `json:"efsAccessPointId,omitempty"`
+	EbsId            string `json:"ebsId,omitempty"`
+	EbsInitJobStatus string `json:"ebsInitJobStatus,omitempty"`
+	FsxId            string `json:"fsxId,omitempty"`
+	// FsxVolumeStatus tracks restore or creation progress of the FSx volume
+	FsxVolumeStatus string `json:"fsxVolumeStatus,omitempty"`
+	// VolumeSize is the size in Gi shared home was last provisioned or expanded to
+	VolumeSize   int64  `json:"volumeSize,omitempty"`
+	ResizeStatus string `json:"resizeStatus,omitempty"`
+}
+
+const (
+	// ConditionSharedHomeReady is true when shared home PVC is bound and, for EBS volumes, nfs server is ready
+	ConditionSharedHomeReady = "SharedHomeReady"
+	// ConditionStalled is true when reconciliation stopped on an error that needs Jira spec to change
+	ConditionStalled = "Stalled"
+	// ConditionPaused is true when reconciliation is paused
+	ConditionPaused = "Paused"
+	// ConditionMaintenance is true when Jira is scaled down for maintenance
+	ConditionMaintenance = "Maintenance"
+)
+
+const (
+	// PausedAnnotation set to "true" suspends reconciliation of a Jira
+	PausedAnnotation = "app.atlassian.com/paused"
+	// LatestDatabaseSnapshotAnnotation is the RDS snapshot taken by the latest backup of a Jira
+	LatestDatabaseSnapshotAnnotation = "app.atlassian.com/latest-database-snapshot"
+	// LatestSharedHomeSnapshotAnnotation is the EBS or FSx snapshot taken by the latest backup of a Jira
+	LatestSharedHomeSnapshotAnnotation = "app.atlassian.com/latest-shared-home-snapshot"
+	// ExternalDNSDisabledAnnotation set to "true" removes the external-dns hostname from Jira ingress, so that it does
+	// not serve the hostname. Blue/green deployment sets it on all stacks but the live one
+	ExternalDNSDisabledAnnotation = "app.atlassian.com/external-dns-disabled"
+	// BlueGreenParentLabel is the name of the Jira a blue/green stack was deployed for
+	BlueGreenParentLabel = "app.atlassian.com/blue-green-parent"
+	// LegacyNamesAnnotation set to "true" keeps the fixed names and nfs server selector of children of Jiras created
+	// before names were derived from the Jira, as renaming them would orphan data and selectors are immutable.
+	// The operator sets it to "false" on other Jiras the first time it reconciles them
+	LegacyNamesAnnotation = "app.atlassian.com/legacy-names"
+	// HelmReleaseFinalizer uninstalls the release of Jira installed with helm delivery when Jira is deleted
+	HelmReleaseFinalizer = "app.atlassian.com/helm-release"
+)
+
+// StepStatus records the outcome and timing of a reconciliation step
+type StepStatus struct {
+	Name  string `json:"name"`
+	Ready bool   `json:"ready"`
+	// StartedAt is when the step last started working towards ready
+	StartedAt *metav1.Time `json:"startedAt,omitempty"`
+	// CompletedAt is when the step last became ready
+	CompletedAt *metav1.Time `json:"completedAt,omitempty"`
+	Error       string       `json:"error,omitempty"`
 }
 
 // JiraStatus defines the observed state of Jira
 type JiraStatus struct {
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Namespace Jira and its dependencies are deployed to
+	Namespace              string                 `json:"namespace,omitempty"`
 	RDS                    RDSStatus              `json:"rds,omitempty"`
 	AppStatus              AppStatus              `json:"app,omitempty"`
 	SharedFilesystemStatus SharedFilesystemStatus `json:"sharedFs,omitempty"`
+	Promotion              PromotionStatus        `json:"promotion,omitempty"`
+	BlueGreen              BlueGreenStatus        `json:"blueGreen,omitempty"`
+	DNS                    DNSStatus              `json:"dns,omitempty"`
+	// Steps of the reconciliation pipeline in the order they run
+	Steps []StepStatus `json:"steps,omitempty"`
+	// ReadyAt is when all steps of Jira were first ready
+	ReadyAt *metav1.Time `json:"readyAt,omitempty"`
 }
 
 //+kubebuilder:object:root=true
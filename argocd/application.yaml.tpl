@@ -0,0 +1,60 @@
+apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: {{ .namespace }}
+  namespace: {{ .argoCDNamespace }}
+{{- if not .retainOnDelete }}
+  # deletes Jira workload along with the application, as ApplicationSet does for the applications it generates
+  finalizers:
+  - resources-finalizer.argocd.argoproj.io
+  ownerReferences:
+  - apiVersion: app.atlassian.com/v1
+    blockOwnerDeletion: true
+    controller: true
+    kind: Jira
+    name: {{ .namespace }}
+    uid: {{ .uid }}
+{{- end }}
+spec:
+  project: {{ .argoCDProject }}
+  syncPolicy:
+    syncOptions:
+    - ApplyOutOfSyncOnly={{ .applyOutOfSyncOnly }}
+{{- if .autoSync }}
+    automated: {}
+{{- end }}
+  sources:
+    - chart: {{ .helmChart }}
+      repoURL: {{ .helmChartRepo }}
+      targetRevision: {{ .helmChartVersion }}
+      helm:
+        releaseName: {{ .namespace }}
+{{- if .helmParameters }}
+        parameters:
+        {{- range .helmParameters }}
+          - name: {{ printf "%q" .Name }}
+            value: {{ printf "%q" .Value }}
+          {{- if .ForceString }}
+            forceString: true
+          {{- end }}
+        {{- end }}
+{{- end }}
+        valuesObject: {{ .helmValues }}
+{{- if .valuesFiles }}
+        valueFiles:
+            {{- range .valuesFiles }}
+            - {{ . }}
+            {{- end }}
+{{- end }}
+{{- if .helmValuesGitRepo }}
+    - repoURL: {{ .helmValuesGitRepo }}
+      targetRevision: {{ .helmValuesRepoRevision }}
+      ref: values
+{{- end }}
+  destination:
+{{- if .destinationName }}
+    name: {{ .destinationName }}
+{{- else }}
+    server: {{ .destinationServer }}
+{{- end }}
+    namespace: {{ .destinationNamespace }}
\ No newline at end of file
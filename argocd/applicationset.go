@@ -1,73 +1,80 @@
 package argocd
 
 import (
+	"encoding/json"
 	"fmt"
 	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+	"github.com/atlassian-labs/jira-operator/helm"
+	"github.com/atlassian-labs/jira-operator/k8s"
+	"github.com/atlassian-labs/jira-operator/product"
 	"os"
 	"strconv"
 	"strings"
 	"text/template"
 )
 
-func ProcessApplicationSetTemplate(jira appv1.Jira) (err error) {
-	subnets := strings.Join(jira.Spec.Network.SubnetIDs, ",")
-	albTags := strings.Join([]string{
-		"service_name=" + jira.Name,
-		"Name=" + jira.Name,
-		"business_unit=WorkplaceTechnology",
-		"resource_owner=mgibson",
-	}, ",")
+// GetKind returns the kind of Argo CD resource Jira is deployed with
+func GetKind(jira appv1.Jira) string {
+	if jira.Spec.ArgoCD.Kind == appv1.ArgoCDKindApplication {
+		return appv1.ArgoCDKindApplication
+	}
+	return appv1.ArgoCDKindApplicationSet
+}
 
-	ingressAnnotations := map[string]interface{}{
-		"alb.ingress.kubernetes.io/certificate-arn":         "arn:aws:acm:ap-southeast-2:629205377521:certificate/7d398889-d2ed-42e4-94e7-16fded6498f1",
-		"alb.ingress.kubernetes.io/healthcheck-path":        "/status",
-		"alb.ingress.kubernetes.io/listen-ports":            "[{\"HTTP\": 80}, {\"HTTPS\": 443}]",
-		"alb.ingress.kubernetes.io/scheme":                  "internal",
-		"alb.ingress.kubernetes.io/ssl-policy":              "ELBSecurityPolicy-FS-1-2-Res-2020-10",
-		"alb.ingress.kubernetes.io/subnets":                 subnets,
-		"alb.ingress.kubernetes.io/tags":                    albTags,
-		"alb.ingress.kubernetes.io/target-group-attributes": "stickiness.enabled=true,stickiness.lb_cookie.duration_seconds=43200",
-		"alb.ingress.kubernetes.io/target-type":             "ip",
-		"external-dns.alpha.kubernetes.io/hostname":         jira.Spec.Hostname,
+// ProcessApplicationTemplate renders the Argo CD Application or ApplicationSet of Jira and returns the path of the rendered file
+func ProcessApplicationTemplate(jira appv1.Jira) (outputFile string, err error) {
+	helmValues, err := helm.GetValues(jira)
+	if err != nil {
+		return "", err
+	}
+	// JSON is valid YAML, and serializing it on a single line leaves no indentation to get wrong in the template
+	helmValuesJson, err := json.Marshal(helmValues)
+	if err != nil {
+		return "", err
 	}
 
 	vars := make(map[string]interface{})
 	vars["namespace"] = jira.Name
+	vars["destinationNamespace"] = k8s.GetNamespaceName(jira)
+	vars["destinationName"] = jira.Spec.ArgoCD.Destination.Name
+	vars["destinationServer"] = jira.Spec.ArgoCD.Destination.Server
+	if jira.Spec.ArgoCD.Destination.Name == "" && jira.Spec.ArgoCD.Destination.Server == "" {
+		vars["destinationServer"] = appv1.InClusterServer
+	}
 	vars["argoCDNamespace"] = jira.Spec.ArgoCD.Namespace
 	vars["argoCDProject"] = jira.Spec.ArgoCD.Project
 	vars["autoSync"] = jira.Spec.ArgoCD.SyncPolicy.AutoSync
 	vars["retainOnDelete"] = jira.Spec.ArgoCD.RetainOnDelete
 	vars["uid"] = jira.UID
 	vars["applyOutOfSyncOnly"] = strconv.FormatBool(jira.Spec.ArgoCD.SyncPolicy.ApplyOutOfSyncOnly)
+	vars["helmChart"] = product.Get(jira).Chart
 	vars["helmChartRepo"] = jira.Spec.ArgoCD.HelmChart.RepoURL
 	vars["helmChartVersion"] = jira.Spec.ArgoCD.HelmChart.Version
 	vars["helmValuesGitRepo"] = jira.Spec.ArgoCD.HelmValues.GitRepo
 	vars["helmValuesRepoRevision"] = jira.Spec.ArgoCD.HelmValues.GitRevision
 	vars["valuesFiles"] = jira.Spec.ArgoCD.HelmValues.HelmValuesFiles
-	vars["appHostname"] = jira.Spec.Hostname
-	vars["IngressAnnotations"] = ingressAnnotations
-	vars["inLineValues"] = jira.Spec.ArgoCD.HelmValues.ValueOverrides
+	vars["helmValues"] = string(helmValuesJson)
+	vars["helmParameters"] = helm.GetParameters(jira)
 
-	tmplFile := "argocd/applicationset.yaml.tpl"
-	outputFile := fmt.Sprintf("argocd/applicationset-%s.yaml", jira.Name)
+	kind := strings.ToLower(GetKind(jira))
+	tmplFile := fmt.Sprintf("argocd/%s.yaml.tpl", kind)
+	outputFile = fmt.Sprintf("argocd/%s-%s.yaml", kind, jira.Name)
 	tmplContent, err := os.ReadFile(tmplFile)
 	if err != nil {
-		return err
+		return "", err
 	}
-	tmpl, err := template.New("applicationset").Funcs(template.FuncMap{
-		"split": strings.Split,
-	}).Parse(string(tmplContent))
+	tmpl, err := template.New(kind).Parse(string(tmplContent))
 	if err != nil {
-		return err
+		return "", err
 	}
 	file, err := os.Create(outputFile)
 	if err != nil {
-		return err
+		return "", err
 	}
 	defer file.Close()
 	err = tmpl.Execute(file, vars)
 	if err != nil {
-		return err
+		return "", err
 	}
-	return nil
+	return outputFile, nil
 }
@@ -17,6 +17,7 @@ spec:
   - list:
       elements:
       - namespace: {{ .namespace }}
+        destinationNamespace: {{ .destinationNamespace }}
   template:
     metadata:
       name: '{{"{{ namespace }}"}}'
@@ -29,28 +30,37 @@ spec:
         automated: {}
 {{- end }}
       sources:
-        - chart: jira
+        - chart: {{ .helmChart }}
           repoURL: {{ .helmChartRepo }}
           targetRevision: {{ .helmChartVersion }}
           helm:
             releaseName: '{{"{{ namespace }}"}}'
-            values: |
-            {{- range $line := split .inLineValues "\n" }}
-                {{ $line }}
-            {{- end -}}
-              ingress:
-                    host: {{ .appHostname }}
-                    annotations:
-                        {{- range $key, $value := .IngressAnnotations }}
-                        '{{ $key }}': '{{ $value }}'
-                        {{- end }}
+{{- if .helmParameters }}
+            parameters:
+            {{- range .helmParameters }}
+              - name: {{ printf "%q" .Name }}
+                value: {{ printf "%q" .Value }}
+              {{- if .ForceString }}
+                forceString: true
+              {{- end }}
+            {{- end }}
+{{- end }}
+            valuesObject: {{ .helmValues }}
+{{- if .valuesFiles }}
             valueFiles:
                 {{- range .valuesFiles }}
                 - {{ . }}
                 {{- end }}
+{{- end }}
+{{- if .helmValuesGitRepo }}
         - repoURL: {{ .helmValuesGitRepo }}
           targetRevision: {{ .helmValuesRepoRevision }}
           ref: values
+{{- end }}
       destination:
-        server: "https://kubernetes.default.svc"
-        namespace: '{{"{{ namespace }}"}}'
\ No newline at end of file
+{{- if .destinationName }}
+        name: {{ .destinationName }}
+{{- else }}
+        server: {{ .destinationServer }}
+{{- end }}
+        namespace: '{{"{{ destinationNamespace }}"}}'
\ No newline at end of file
@@ -0,0 +1,84 @@
+package argocd
+
+import (
+	"encoding/json"
+	"fmt"
+	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+	"github.com/atlassian-labs/jira-operator/k8s"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"strings"
+)
+
+// application is the part of Argo CD Application reported in Jira status
+type application struct {
+	Status struct {
+		Sync struct {
+			Status    string   `json:"status"`
+			Revision  string   `json:"revision"`
+			Revisions []string `json:"revisions"`
+		} `json:"sync"`
+		Health struct {
+			Status string `json:"status"`
+		} `json:"health"`
+		OperationState *struct {
+			Phase      string       `json:"phase"`
+			Message    string       `json:"message"`
+			FinishedAt *metav1.Time `json:"finishedAt"`
+		} `json:"operationState"`
+		Conditions []struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		} `json:"conditions"`
+		Resources []struct {
+			Kind      string `json:"kind"`
+			Namespace string `json:"namespace"`
+			Name      string `json:"name"`
+			Health    *struct {
+				Status  string `json:"status"`
+				Message string `json:"message"`
+			} `json:"health"`
+		} `json:"resources"`
+	} `json:"status"`
+}
+
+// GetAppStatus returns sync, health, last operation, conditions and degraded resources of Jira application.
+// The application is read with kubectl, as Argo CD types are not imported
+func GetAppStatus(jira appv1.Jira) (appStatus appv1.AppStatus, err error) {
+	args := []string{"get", "application/" + jira.Name, "-n", jira.Spec.ArgoCD.Namespace, "-o", "json"}
+	output, err := k8s.RunKubectl(args)
+	if err != nil {
+		return appStatus, fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return parseAppStatus(output)
+}
+
+// parseAppStatus reads Jira status from Argo CD Application JSON
+func parseAppStatus(output []byte) (appStatus appv1.AppStatus, err error) {
+	var app application
+	err = json.Unmarshal(output, &app)
+	if err != nil {
+		return appStatus, err
+	}
+
+	appStatus.Sync = app.Status.Sync.Status
+	appStatus.Health = app.Status.Health.Status
+	// applications with a values source have a revision per source
+	appStatus.Revisions = app.Status.Sync.Revisions
+	if len(appStatus.Revisions) == 0 && app.Status.Sync.Revision != "" {
+		appStatus.Revisions = []string{app.Status.Sync.Revision}
+	}
+	if app.Status.OperationState != nil {
+		appStatus.OperationPhase = app.Status.OperationState.Phase
+		appStatus.OperationMessage = app.Status.OperationState.Message
+		appStatus.LastSyncedAt = app.Status.OperationState.FinishedAt
+	}
+	for _, condition := range app.Status.Conditions {
+		appStatus.Conditions = append(appStatus.Conditions, appv1.AppCondition{Type: condition.Type, Message: condition.Message})
+	}
+	for _, resource := range app.Status.Resources {
+		if resource.Health != nil && (resource.Health.Status == "Degraded" || resource.Health.Status == "Missing") {
+			appStatus.DegradedResources = append(appStatus.DegradedResources, resource.Kind+"/"+resource.Namespace+"/"+resource.Name)
+		}
+	}
+	return appStatus, nil
+}
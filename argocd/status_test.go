@@ -0,0 +1,80 @@
+package argocd
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+)
+
+func TestParseAppStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    appv1.AppStatus
+		wantErr bool
+	}{
+		{
+			name:   "application not reconciled yet",
+			output: `{"status": {}}`,
+			want:   appv1.AppStatus{},
+		},
+		{
+			name: "single source",
+			output: `{"status": {
+				"sync": {"status": "Synced", "revision": "1.10.0"},
+				"health": {"status": "Healthy"}
+			}}`,
+			want: appv1.AppStatus{Sync: "Synced", Health: "Healthy", Revisions: []string{"1.10.0"}},
+		},
+		{
+			name: "failed sync of chart and values sources",
+			output: `{"status": {
+				"sync": {"status": "OutOfSync", "revisions": ["1.10.0", "0a1b2c3"]},
+				"health": {"status": "Degraded"},
+				"operationState": {"phase": "Failed", "message": "one or more objects failed to apply", "finishedAt": "2023-07-01T10:00:00Z"},
+				"conditions": [{"type": "ComparisonError", "message": "values.yaml: no such file or directory"}],
+				"resources": [
+					{"kind": "StatefulSet", "namespace": "jira", "name": "jira", "health": {"status": "Degraded"}},
+					{"kind": "Service", "namespace": "jira", "name": "jira", "health": {"status": "Healthy"}},
+					{"kind": "Ingress", "namespace": "jira", "name": "jira", "health": {"status": "Missing"}},
+					{"kind": "ConfigMap", "namespace": "jira", "name": "jira-jvm-config"}
+				]
+			}}`,
+			want: appv1.AppStatus{
+				Sync:              "OutOfSync",
+				Health:            "Degraded",
+				Revisions:         []string{"1.10.0", "0a1b2c3"},
+				OperationPhase:    "Failed",
+				OperationMessage:  "one or more objects failed to apply",
+				Conditions:        []appv1.AppCondition{{Type: "ComparisonError", Message: "values.yaml: no such file or directory"}},
+				DegradedResources: []string{"StatefulSet/jira/jira", "Ingress/jira/jira"},
+			},
+		},
+		{
+			name:    "invalid output",
+			output:  "error: the server doesn't have a resource type",
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parseAppStatus([]byte(test.output))
+			if (err != nil) != test.wantErr {
+				t.Fatalf("parseAppStatus() error = %v, want error %t", err, test.wantErr)
+			}
+			if got.LastSyncedAt != nil {
+				if want := time.Date(2023, 7, 1, 10, 0, 0, 0, time.UTC); !got.LastSyncedAt.Time.Equal(want) {
+					t.Errorf("LastSyncedAt = %s, want %s", got.LastSyncedAt, want)
+				}
+				got.LastSyncedAt = nil
+			} else if test.want.OperationPhase != "" {
+				t.Error("LastSyncedAt is not set from the finished operation")
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("parseAppStatus() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
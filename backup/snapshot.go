@@ -0,0 +1,156 @@
+package backup
+
+import (
+	"errors"
+	"fmt"
+	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+	"github.com/atlassian-labs/jira-operator/naming"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/fsx"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"time"
+)
+
+// ErrSnapshotFailed is returned when a snapshot failed or was deleted, as opposed to AWS API errors which can be retried
+var ErrSnapshotFailed = errors.New("snapshot failed")
+
+// GetSnapshotName returns the name of snapshots of Jira taken at a given time, which is the same as kubectl jira backup names them
+func GetSnapshotName(jira appv1.Jira, at time.Time) string {
+	return fmt.Sprintf("%s-%s", naming.ClusterScoped(jira), at.UTC().Format("20060102150405"))
+}
+
+// CreateDatabaseSnapshot starts a snapshot of Jira RDS instance and returns its identifier
+func CreateDatabaseSnapshot(jira appv1.Jira, name string) (snapshotId string, err error) {
+	sess, err := getSession(jira)
+	if err != nil {
+		return "", err
+	}
+	output, err := rds.New(sess).CreateDBSnapshot(&rds.CreateDBSnapshotInput{
+		DBInstanceIdentifier: aws.String(naming.ClusterScoped(jira)),
+		DBSnapshotIdentifier: aws.String(name),
+	})
+	if err != nil {
+		return "", err
+	}
+	return aws.StringValue(output.DBSnapshot.DBSnapshotIdentifier), nil
+}
+
+// IsDatabaseSnapshotAvailable returns whether an RDS snapshot is complete, and an error when it failed
+func IsDatabaseSnapshotAvailable(jira appv1.Jira, snapshotId string) (available bool, err error) {
+	sess, err := getSession(jira)
+	if err != nil {
+		return false, err
+	}
+	output, err := rds.New(sess).DescribeDBSnapshots(&rds.DescribeDBSnapshotsInput{DBSnapshotIdentifier: aws.String(snapshotId)})
+	if err != nil {
+		return false, err
+	}
+	if len(output.DBSnapshots) == 0 {
+		return false, fmt.Errorf("%w: RDS snapshot %s not found", ErrSnapshotFailed, snapshotId)
+	}
+	status := aws.StringValue(output.DBSnapshots[0].Status)
+	if status == "failed" {
+		return false, fmt.Errorf("%w: RDS snapshot %s", ErrSnapshotFailed, snapshotId)
+	}
+	return status == "available", nil
+}
+
+// CreateEbsSnapshot starts a snapshot of the EBS volume of shared home and returns its ID
+func CreateEbsSnapshot(jira appv1.Jira, volumeId string, name string) (snapshotId string, err error) {
+	sess, err := getSession(jira)
+	if err != nil {
+		return "", err
+	}
+	output, err := ec2.New(sess).CreateSnapshot(&ec2.CreateSnapshotInput{
+		VolumeId:    aws.String(volumeId),
+		Description: aws.String(name),
+	})
+	if err != nil {
+		return "", err
+	}
+	return aws.StringValue(output.SnapshotId), nil
+}
+
+// IsEbsSnapshotCompleted returns whether an EBS snapshot is complete, and an error when it failed
+func IsEbsSnapshotCompleted(jira appv1.Jira, snapshotId string) (completed bool, err error) {
+	sess, err := getSession(jira)
+	if err != nil {
+		return false, err
+	}
+	output, err := ec2.New(sess).DescribeSnapshots(&ec2.DescribeSnapshotsInput{SnapshotIds: []*string{aws.String(snapshotId)}})
+	if err != nil {
+		return false, err
+	}
+	if len(output.Snapshots) == 0 {
+		return false, fmt.Errorf("%w: EBS snapshot %s not found", ErrSnapshotFailed, snapshotId)
+	}
+	state := aws.StringValue(output.Snapshots[0].State)
+	if state == ec2.SnapshotStateError {
+		return false, fmt.Errorf("%w: EBS snapshot %s: %s", ErrSnapshotFailed, snapshotId, aws.StringValue(output.Snapshots[0].StateMessage))
+	}
+	return state == ec2.SnapshotStateCompleted, nil
+}
+
+// CreateFsxSnapshot starts a snapshot of the FSx volume of shared home and returns its ID
+func CreateFsxSnapshot(jira appv1.Jira, volumeId string, name string) (snapshotId string, err error) {
+	sess, err := getSession(jira)
+	if err != nil {
+		return "", err
+	}
+	output, err := fsx.New(sess).CreateSnapshot(&fsx.CreateSnapshotInput{
+		VolumeId: aws.String(volumeId),
+		Name:     aws.String(name),
+	})
+	if err != nil {
+		return "", err
+	}
+	return aws.StringValue(output.Snapshot.SnapshotId), nil
+}
+
+// IsFsxSnapshotAvailable returns whether an FSx snapshot is complete, and an error when it is being deleted
+func IsFsxSnapshotAvailable(jira appv1.Jira, snapshotId string) (available bool, err error) {
+	sess, err := getSession(jira)
+	if err != nil {
+		return false, err
+	}
+	output, err := fsx.New(sess).DescribeSnapshots(&fsx.DescribeSnapshotsInput{SnapshotIds: []*string{aws.String(snapshotId)}})
+	if err != nil {
+		return false, err
+	}
+	if len(output.Snapshots) == 0 {
+		return false, fmt.Errorf("%w: FSx snapshot %s not found", ErrSnapshotFailed, snapshotId)
+	}
+	lifecycle := aws.StringValue(output.Snapshots[0].Lifecycle)
+	if lifecycle == fsx.SnapshotLifecycleDeleting {
+		return false, fmt.Errorf("%w: FSx snapshot %s is being deleted", ErrSnapshotFailed, snapshotId)
+	}
+	return lifecycle == fsx.SnapshotLifecycleAvailable, nil
+}
+
+// SetSnapshots points Jira spec to the snapshots to restore database and shared home from
+func SetSnapshots(jira *appv1.Jira, databaseSnapshotId string, sharedHomeSnapshotId string) error {
+	jira.Spec.Database.SnapshotID = databaseSnapshotId
+	// shared home type is inferred from snapshots when it is not set, so it is set before they change
+	sharedFsType := jira.Spec.SharedFS.GetType()
+	jira.Spec.SharedFS.Type = sharedFsType
+	switch sharedFsType {
+	case appv1.SharedFSTypeEbs:
+		if sharedHomeSnapshotId == "" {
+			return fmt.Errorf("no EBS snapshot to restore shared home from")
+		}
+		jira.Spec.SharedFS.Ebs.SnapshotId = sharedHomeSnapshotId
+	case appv1.SharedFSTypeFsx:
+		if sharedHomeSnapshotId == "" {
+			return fmt.Errorf("no FSx snapshot to restore shared home from")
+		}
+		jira.Spec.SharedFS.Fsx.SnapshotId = sharedHomeSnapshotId
+	}
+	return nil
+}
+
+// getSession uses the credentials of the operator, as Crossplane AWS provider has no snapshot resources
+func getSession(jira appv1.Jira) (*session.Session, error) {
+	return session.NewSession(&aws.Config{Region: aws.String(jira.Spec.AWSRegion)})
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+	"github.com/atlassian-labs/jira-operator/backup"
+	"github.com/atlassian-labs/jira-operator/naming"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"os/exec"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strings"
+	"time"
+)
+
+// runAws runs aws cli in the region of a Jira and returns its text output
+func runAws(jira appv1.Jira, args ...string) (output string, err error) {
+	args = append(args, "--region", jira.Spec.AWSRegion, "--output", "text")
+	out, err := exec.Command("aws", args...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("aws %s: %w: %s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func runBackup(ctx context.Context, c client.Client, args []string, usage string) error {
+	jira, err := getJira(ctx, c, args, usage)
+	if err != nil {
+		return err
+	}
+	flags := flag.NewFlagSet("backup", flag.ContinueOnError)
+	waitForSnapshots := flags.Bool("wait", true, "Wait for snapshots to be available")
+	err = flags.Parse(args[1:])
+	if err != nil {
+		return err
+	}
+
+	snapshotName := fmt.Sprintf("%s-%s", naming.ClusterScoped(jira), time.Now().UTC().Format("20060102150405"))
+
+	fmt.Println("Creating RDS snapshot " + snapshotName)
+	databaseSnapshotId, err := runAws(jira, "rds", "create-db-snapshot", "--db-instance-identifier", naming.ClusterScoped(jira),
+		"--db-snapshot-identifier", snapshotName, "--query", "DBSnapshot.DBSnapshotIdentifier")
+	if err != nil {
+		return err
+	}
+
+	sharedHomeSnapshotId := ""
+	switch jira.Spec.SharedFS.GetType() {
+	case appv1.SharedFSTypeEbs:
+		fmt.Println("Creating EBS snapshot of " + jira.Status.SharedFilesystemStatus.EbsId)
+		sharedHomeSnapshotId, err = runAws(jira, "ec2", "create-snapshot", "--volume-id", jira.Status.SharedFilesystemStatus.EbsId,
+			"--description", snapshotName, "--query", "SnapshotId")
+	case appv1.SharedFSTypeFsx:
+		// FsxId is the CSI volume handle of shared home, which is the FSx volume ID
+		fmt.Println("Creating FSx snapshot of " + jira.Status.SharedFilesystemStatus.FsxId)
+		sharedHomeSnapshotId, err = runAws(jira, "fsx", "create-snapshot", "--volume-id", jira.Status.SharedFilesystemStatus.FsxId,
+			"--name", snapshotName, "--query", "Snapshot.SnapshotId")
+	default:
+		fmt.Println("Shared home on EFS is not snapshotted, back it up with AWS Backup")
+	}
+	if err != nil {
+		return err
+	}
+
+	if *waitForSnapshots {
+		fmt.Println("Waiting for snapshots to be available")
+		_, err = runAws(jira, "rds", "wait", "db-snapshot-available", "--db-snapshot-identifier", databaseSnapshotId)
+		if err != nil {
+			return err
+		}
+		switch jira.Spec.SharedFS.GetType() {
+		case appv1.SharedFSTypeEbs:
+			_, err = runAws(jira, "ec2", "wait", "snapshot-completed", "--snapshot-ids", sharedHomeSnapshotId)
+		case appv1.SharedFSTypeFsx:
+			err = waitForFsxSnapshot(ctx, jira, sharedHomeSnapshotId)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	// latest snapshots are recorded on Jira, clone and restore use them
+	patch := client.MergeFrom(jira.DeepCopy())
+	if jira.Annotations == nil {
+		jira.Annotations = map[string]string{}
+	}
+	jira.Annotations[appv1.LatestDatabaseSnapshotAnnotation] = databaseSnapshotId
+	if sharedHomeSnapshotId != "" {
+		jira.Annotations[appv1.LatestSharedHomeSnapshotAnnotation] = sharedHomeSnapshotId
+	}
+	err = c.Patch(ctx, &jira, patch)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("jira %s backed up: database snapshot %s, shared home snapshot %s\n", jira.Name, databaseSnapshotId, valueOrNone(sharedHomeSnapshotId))
+	return nil
+}
+
+// waitForFsxSnapshot polls the lifecycle of an FSx snapshot until it is available, as aws cli has no waiter for it
+func waitForFsxSnapshot(ctx context.Context, jira appv1.Jira, snapshotId string) error {
+	return wait.PollUntilContextTimeout(ctx, 15*time.Second, time.Hour, true, func(ctx context.Context) (bool, error) {
+		lifecycle, err := runAws(jira, "fsx", "describe-snapshots", "--snapshot-ids", snapshotId, "--query", "Snapshots[0].Lifecycle")
+		if err != nil {
+			return false, err
+		}
+		if lifecycle == "DELETING" || lifecycle == "FAILED" {
+			return false, fmt.Errorf("FSx snapshot %s is %s", snapshotId, lifecycle)
+		}
+		return lifecycle == "AVAILABLE", nil
+	})
+}
+
+func runRestore(ctx context.Context, c client.Client, args []string, usage string) error {
+	jira, err := getJira(ctx, c, args, usage)
+	if err != nil {
+		return err
+	}
+	flags := flag.NewFlagSet("restore", flag.ContinueOnError)
+	confirm := flags.Bool("confirm", false, "Confirm that Jira and all its resources are deleted and recreated")
+	databaseSnapshotId := flags.String("database-snapshot", jira.Annotations[appv1.LatestDatabaseSnapshotAnnotation], "RDS snapshot to restore")
+	sharedHomeSnapshotId := flags.String("shared-home-snapshot", jira.Annotations[appv1.LatestSharedHomeSnapshotAnnotation], "EBS or FSx snapshot to restore")
+	err = flags.Parse(args[1:])
+	if err != nil {
+		return err
+	}
+	if *databaseSnapshotId == "" {
+		return fmt.Errorf("jira %s has no backup, run kubectl jira backup %s first", jira.Name, jira.Name)
+	}
+	if !*confirm {
+		return fmt.Errorf("restore deletes jira %s with its database and shared home, rerun with --confirm", jira.Name)
+	}
+
+	restored := appv1.Jira{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        jira.Name,
+			Labels:      jira.Labels,
+			Annotations: jira.Annotations,
+		},
+		Spec: *jira.Spec.DeepCopy(),
+	}
+	err = backup.SetSnapshots(&restored, *databaseSnapshotId, *sharedHomeSnapshotId)
+	if err != nil {
+		return err
+	}
+
+	// foreground deletion removes Jira only when all its resources are gone, so the new ones do not conflict with them
+	fmt.Printf("Deleting jira %s\n", jira.Name)
+	err = c.Delete(ctx, &jira, client.PropagationPolicy(metav1.DeletePropagationForeground))
+	if err != nil {
+		return err
+	}
+	err = wait.PollUntilContextTimeout(ctx, 10*time.Second, time.Hour, true, func(ctx context.Context) (bool, error) {
+		err := c.Get(ctx, client.ObjectKey{Name: jira.Name}, &appv1.Jira{})
+		if errors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	})
+	if err != nil {
+		return err
+	}
+
+	err = c.Create(ctx, &restored)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("jira %s restored from database snapshot %s, shared home snapshot %s\n", restored.Name, *databaseSnapshotId, valueOrNone(*sharedHomeSnapshotId))
+	return nil
+}
+
+func valueOrNone(value string) string {
+	if value == "" {
+		return "none"
+	}
+	return value
+}
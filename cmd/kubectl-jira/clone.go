@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+	"github.com/atlassian-labs/jira-operator/backup"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func runClone(ctx context.Context, c client.Client, args []string, usage string) error {
+	source, err := getJira(ctx, c, args, usage)
+	if err != nil {
+		return err
+	}
+	// the clone would otherwise publish the hostname of its source, through external-dns or its Route53 record
+	if len(args) < 3 {
+		return fmt.Errorf("usage: kubectl jira %s", usage)
+	}
+	if args[2] == source.Spec.Hostname {
+		return fmt.Errorf("hostname %s is served by %s, the clone needs its own", args[2], source.Name)
+	}
+
+	databaseSnapshotId := source.Annotations[appv1.LatestDatabaseSnapshotAnnotation]
+	if databaseSnapshotId == "" {
+		return fmt.Errorf("jira %s has no backup, run kubectl jira backup %s first", source.Name, source.Name)
+	}
+
+	clone := appv1.Jira{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: args[1],
+		},
+		Spec: *source.Spec.DeepCopy(),
+	}
+	clone.Spec.Hostname = args[2]
+	// a created namespace is named after the clone rather than shared with its source
+	if clone.Spec.TargetNamespace.Mode == "" || clone.Spec.TargetNamespace.Mode == appv1.NamespaceModeCreate {
+		clone.Spec.TargetNamespace.Name = ""
+	}
+	err = backup.SetSnapshots(&clone, databaseSnapshotId, source.Annotations[appv1.LatestSharedHomeSnapshotAnnotation])
+	if err != nil {
+		return err
+	}
+
+	err = c.Create(ctx, &clone)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("jira %s cloned from %s\n", clone.Name, source.Name)
+	return nil
+}
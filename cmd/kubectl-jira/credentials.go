@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/atlassian-labs/jira-operator/k8s"
+	"github.com/atlassian-labs/jira-operator/naming"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// runRotateCredentials sets a new password in the database secret. Crossplane updates RDS master password
+// from the secret, and Jira picks the new password up when its pods restart
+func runRotateCredentials(ctx context.Context, c client.Client, args []string, usage string) error {
+	jira, err := getJira(ctx, c, args, usage)
+	if err != nil {
+		return err
+	}
+
+	var secret corev1.Secret
+	err = c.Get(ctx, client.ObjectKey{Name: naming.DatabaseSecret(jira), Namespace: jira.Status.Namespace}, &secret)
+	if err != nil {
+		return err
+	}
+	patch := client.MergeFrom(secret.DeepCopy())
+	secret.Data["password"] = []byte(k8s.GeneratePasswd(26))
+	err = c.Patch(ctx, &secret, patch)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("secret %s/%s updated with a new password, restart Jira pods once RDS is available\n", secret.Namespace, secret.Name)
+	return nil
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// kubectl-jira is a kubectl plugin for day-2 operations on Jira custom resources
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+	database "github.com/crossplane-contrib/provider-aws/apis/database/v1beta1"
+	ec2 "github.com/crossplane-contrib/provider-aws/apis/ec2/v1alpha1"
+	efs "github.com/crossplane-contrib/provider-aws/apis/efs/v1alpha1"
+	rds "github.com/crossplane-contrib/provider-aws/apis/rds/v1alpha1"
+	route53 "github.com/crossplane-contrib/provider-aws/apis/route53/v1alpha1"
+	snapshot "github.com/kubernetes-csi/external-snapshotter/client/v6/apis/volumesnapshot/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	_ "k8s.io/client-go/plugin/pkg/client/auth"
+	"os"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(rds.AddToScheme(scheme))
+	utilruntime.Must(database.SchemeBuilder.AddToScheme(scheme))
+	utilruntime.Must(efs.AddToScheme(scheme))
+	utilruntime.Must(ec2.AddToScheme(scheme))
+	utilruntime.Must(route53.SchemeBuilder.AddToScheme(scheme))
+	utilruntime.Must(snapshot.AddToScheme(scheme))
+	utilruntime.Must(appv1.AddToScheme(scheme))
+}
+
+type command struct {
+	usage       string
+	description string
+	run         func(ctx context.Context, c client.Client, args []string, usage string) error
+}
+
+var commands = map[string]command{
+	"status":             {"status <jira>", "Show phase, conditions and steps of a Jira", runStatus},
+	"clone":              {"clone <source> <destination> <hostname>", "Create a Jira served on its own hostname from the latest backup of another one", runClone},
+	"backup":             {"backup <jira> [--wait=false]", "Snapshot database and shared home of a Jira", runBackup},
+	"restore":            {"restore <jira> --confirm [--database-snapshot id] [--shared-home-snapshot id]", "Recreate a Jira from its latest backup or the given snapshots", runRestore},
+	"rotate-credentials": {"rotate-credentials <jira>", "Generate a new database password for a Jira", runRotateCredentials},
+	"describe-resources": {"describe-resources <jira>", "List Crossplane, Argo CD and Kubernetes resources of a Jira with their health", runDescribeResources},
+	"pause":              {"pause <jira>", "Suspend reconciliation of a Jira through its paused annotation", runPause},
+	"resume":             {"resume <jira>", "Resume reconciliation of a Jira", runResume},
+}
+
+var commandOrder = []string{"status", "clone", "backup", "restore", "rotate-credentials", "describe-resources", "pause", "resume"}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: kubectl jira [--kubeconfig path] <command> [args]")
+	fmt.Fprintln(os.Stderr, "\nCommands:")
+	for _, name := range commandOrder {
+		fmt.Fprintf(os.Stderr, "  %-80s %s\n", commands[name].usage, commands[name].description)
+	}
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() < 1 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[flag.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", flag.Arg(0))
+		usage()
+		os.Exit(2)
+	}
+
+	c, err := client.New(ctrl.GetConfigOrDie(), client.Options{Scheme: scheme})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	err = cmd.run(context.Background(), c, flag.Args()[1:], cmd.usage)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error: "+err.Error())
+		os.Exit(1)
+	}
+}
+
+// getJira returns the Jira named in the first argument of a command
+func getJira(ctx context.Context, c client.Client, args []string, usage string) (jira appv1.Jira, err error) {
+	if len(args) < 1 {
+		return jira, fmt.Errorf("usage: kubectl jira %s", usage)
+	}
+	err = c.Get(ctx, client.ObjectKey{Name: args[0]}, &jira)
+	return jira, err
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func runPause(ctx context.Context, c client.Client, args []string, usage string) error {
+	return setPaused(ctx, c, args, usage, true)
+}
+
+func runResume(ctx context.Context, c client.Client, args []string, usage string) error {
+	return setPaused(ctx, c, args, usage, false)
+}
+
+// setPaused sets or removes the paused annotation, which the operator checks before reconciling a Jira.
+// Resuming also clears spec.paused, as Jira would otherwise stay paused
+func setPaused(ctx context.Context, c client.Client, args []string, usage string, paused bool) error {
+	jira, err := getJira(ctx, c, args, usage)
+	if err != nil {
+		return err
+	}
+	patch := client.MergeFrom(jira.DeepCopy())
+	if paused {
+		if jira.Annotations == nil {
+			jira.Annotations = map[string]string{}
+		}
+		jira.Annotations[appv1.PausedAnnotation] = "true"
+	} else {
+		delete(jira.Annotations, appv1.PausedAnnotation)
+		jira.Spec.Paused = false
+	}
+	err = c.Patch(ctx, &jira, patch)
+	if err != nil {
+		return err
+	}
+	if paused {
+		fmt.Printf("jira %s paused\n", jira.Name)
+	} else {
+		fmt.Printf("jira %s resumed\n", jira.Name)
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+	"github.com/atlassian-labs/jira-operator/flux"
+	"github.com/atlassian-labs/jira-operator/k8s"
+	database "github.com/crossplane-contrib/provider-aws/apis/database/v1beta1"
+	ec2 "github.com/crossplane-contrib/provider-aws/apis/ec2/v1alpha1"
+	efs "github.com/crossplane-contrib/provider-aws/apis/efs/v1alpha1"
+	rds "github.com/crossplane-contrib/provider-aws/apis/rds/v1alpha1"
+	route53 "github.com/crossplane-contrib/provider-aws/apis/route53/v1alpha1"
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	snapshot "github.com/kubernetes-csi/external-snapshotter/client/v6/apis/volumesnapshot/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"os"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"text/tabwriter"
+)
+
+// ownedKinds are the kinds of resources the operator creates for a Jira, namespaced ones are looked up in Jira namespace
+var ownedKinds = []struct {
+	list       client.ObjectList
+	namespaced bool
+}{
+	{&database.RDSInstanceList{}, false},
+	{&database.DBSubnetGroupList{}, false},
+	{&rds.DBParameterGroupList{}, false},
+	{&ec2.VolumeList{}, false},
+	{&efs.FileSystemList{}, false},
+	{&efs.MountTargetList{}, false},
+	{&efs.AccessPointList{}, false},
+	{&route53.ResourceRecordSetList{}, false},
+	{&appv1.JiraList{}, false},
+	{&corev1.NamespaceList{}, false},
+	{&corev1.PersistentVolumeList{}, false},
+	{&snapshot.VolumeSnapshotContentList{}, false},
+	{&corev1.SecretList{}, true},
+	{&corev1.ConfigMapList{}, true},
+	{&corev1.ServiceAccountList{}, true},
+	{&corev1.ServiceList{}, true},
+	{&corev1.PersistentVolumeClaimList{}, true},
+	{&corev1.ResourceQuotaList{}, true},
+	{&corev1.LimitRangeList{}, true},
+	{&batchv1.JobList{}, true},
+	{&appsv1.StatefulSetList{}, true},
+	{&policyv1.PodDisruptionBudgetList{}, true},
+	{&snapshot.VolumeSnapshotList{}, true},
+}
+
+func runDescribeResources(ctx context.Context, c client.Client, args []string, usage string) error {
+	jira, err := getJira(ctx, c, args, usage)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KIND\tNAMESPACE\tNAME\tHEALTH")
+	for _, kind := range ownedKinds {
+		var listOptions []client.ListOption
+		if kind.namespaced {
+			listOptions = append(listOptions, client.InNamespace(jira.Status.Namespace))
+		}
+		err = c.List(ctx, kind.list, listOptions...)
+		if err != nil {
+			return err
+		}
+		items, err := meta.ExtractList(kind.list)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			obj := item.(client.Object)
+			if !isOwnedBy(obj, jira.UID) {
+				continue
+			}
+			gvk, err := c.GroupVersionKindFor(obj)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", gvk.Kind, valueOrNone(obj.GetNamespace()), obj.GetName(), getHealth(obj))
+		}
+	}
+
+	// Argo CD and Flux types are not imported, see argocd and flux packages, so their resources are read as unstructured
+	deliveryResources := []struct {
+		gvk       schema.GroupVersionKind
+		namespace string
+	}{
+		{schema.GroupVersionKind{Group: "argoproj.io", Version: "v1alpha1", Kind: "ApplicationSet"}, jira.Spec.ArgoCD.Namespace},
+		{schema.GroupVersionKind{Group: "argoproj.io", Version: "v1alpha1", Kind: "Application"}, jira.Spec.ArgoCD.Namespace},
+		{flux.HelmRepositoryGVK, k8s.GetNamespaceName(jira)},
+		{flux.HelmReleaseGVK, k8s.GetNamespaceName(jira)},
+	}
+	for _, deliveryResource := range deliveryResources {
+		kind := deliveryResource.gvk.Kind
+		resource := &unstructured.Unstructured{}
+		resource.SetGroupVersionKind(deliveryResource.gvk)
+		err = c.Get(ctx, client.ObjectKey{Name: jira.Name, Namespace: deliveryResource.namespace}, resource)
+		if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		health := "-"
+		if kind == "Application" {
+			syncStatus, _, _ := unstructured.NestedString(resource.Object, "status", "sync", "status")
+			healthStatus, _, _ := unstructured.NestedString(resource.Object, "status", "health", "status")
+			health = syncStatus + "/" + healthStatus
+		} else if kind == flux.HelmReleaseGVK.Kind {
+			health = getUnstructuredReady(resource)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", kind, resource.GetNamespace(), resource.GetName(), health)
+	}
+
+	// revisions of the release installed by helm delivery are stored in secrets labelled by Helm, which Jira does not own
+	var releaseSecrets corev1.SecretList
+	err = c.List(ctx, &releaseSecrets, client.InNamespace(jira.Status.Namespace), client.MatchingLabels{"owner": "helm", "name": jira.Name})
+	if err != nil {
+		return err
+	}
+	for _, secret := range releaseSecrets.Items {
+		fmt.Fprintf(w, "Secret\t%s\t%s\tStatus=%s\n", secret.Namespace, secret.Name, secret.Labels["status"])
+	}
+	return w.Flush()
+}
+
+func isOwnedBy(obj client.Object, uid types.UID) bool {
+	for _, ownerReference := range obj.GetOwnerReferences() {
+		if ownerReference.UID == uid {
+			return true
+		}
+	}
+	return false
+}
+
+// getHealth summarizes the state of a resource: Ready and Synced conditions of Crossplane resources,
+// and the status fields which tell whether Kubernetes resources are usable
+func getHealth(obj client.Object) string {
+	if managed, ok := obj.(interface {
+		GetCondition(xpv1.ConditionType) xpv1.Condition
+	}); ok {
+		return fmt.Sprintf("Ready=%s Synced=%s", managed.GetCondition(xpv1.TypeReady).Status, managed.GetCondition(xpv1.TypeSynced).Status)
+	}
+	switch resource := obj.(type) {
+	case *corev1.Namespace:
+		return string(resource.Status.Phase)
+	case *corev1.PersistentVolume:
+		return string(resource.Status.Phase)
+	case *corev1.PersistentVolumeClaim:
+		return string(resource.Status.Phase)
+	case *batchv1.Job:
+		return fmt.Sprintf("Succeeded=%d Failed=%d", resource.Status.Succeeded, resource.Status.Failed)
+	case *appsv1.StatefulSet:
+		return fmt.Sprintf("Ready=%d/%d", resource.Status.ReadyReplicas, resource.Status.Replicas)
+	case *policyv1.PodDisruptionBudget:
+		return fmt.Sprintf("DisruptionsAllowed=%d", resource.Status.DisruptionsAllowed)
+	case *snapshot.VolumeSnapshot:
+		if resource.Status != nil && resource.Status.ReadyToUse != nil {
+			return fmt.Sprintf("ReadyToUse=%t", *resource.Status.ReadyToUse)
+		}
+	case *snapshot.VolumeSnapshotContent:
+		if resource.Status != nil && resource.Status.ReadyToUse != nil {
+			return fmt.Sprintf("ReadyToUse=%t", *resource.Status.ReadyToUse)
+		}
+	default:
+		return "Exists"
+	}
+	return "-"
+}
+
+// getUnstructuredReady returns the status of the Ready condition of a Flux resource
+func getUnstructuredReady(resource *unstructured.Unstructured) string {
+	conditions, _, _ := unstructured.NestedSlice(resource.Object, "status", "conditions")
+	for _, condition := range conditions {
+		if conditionMap, ok := condition.(map[string]interface{}); ok && conditionMap["type"] == "Ready" {
+			return fmt.Sprintf("Ready=%v", conditionMap["status"])
+		}
+	}
+	return "-"
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"os"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"text/tabwriter"
+	"time"
+)
+
+func runStatus(ctx context.Context, c client.Client, args []string, usage string) error {
+	jira, err := getJira(ctx, c, args, usage)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Name:       %s\n", jira.Name)
+	fmt.Printf("Namespace:  %s\n", jira.Status.Namespace)
+	fmt.Printf("Phase:      %s\n", getPhase(jira))
+	fmt.Printf("RDS:        %s %s\n", jira.Status.RDS.Status, jira.Status.RDS.Endpoint)
+	fmt.Printf("App:        %s %s\n", jira.Status.AppStatus.Sync, jira.Status.AppStatus.Health)
+	fmt.Printf("Version:    %s (%d/%d ready)\n", jira.Status.AppStatus.Version, jira.Status.AppStatus.ReadyReplicas, jira.Status.AppStatus.Replicas)
+	if jira.Status.BlueGreen.Live != "" {
+		fmt.Printf("Live:       %s %s\n", jira.Status.BlueGreen.Live, jira.Status.BlueGreen.Phase)
+	}
+	if jira.Status.DNS.LoadBalancer != "" {
+		fmt.Printf("DNS:        %s -> %s\n", jira.Spec.Hostname, jira.Status.DNS.LoadBalancer)
+	}
+	if rollout := jira.Status.Promotion.Rollout; rollout != nil {
+		fmt.Printf("Rollout:    %s %s\n", rollout.Phase, rollout.To.String())
+	}
+
+	fmt.Println("\nConditions:")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  TYPE\tSTATUS\tREASON\tAGE\tMESSAGE")
+	for _, condition := range jira.Status.Conditions {
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n", condition.Type, condition.Status, condition.Reason, age(condition.LastTransitionTime.Time), condition.Message)
+	}
+	w.Flush()
+
+	fmt.Println("\nSteps:")
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  STEP\tREADY\tDURATION\tCOMPLETED\tERROR")
+	for _, step := range jira.Status.Steps {
+		stepDuration, completed := "-", "-"
+		if step.StartedAt != nil {
+			end := time.Now()
+			if step.CompletedAt != nil {
+				end = step.CompletedAt.Time
+				completed = age(step.CompletedAt.Time) + " ago"
+			}
+			stepDuration = duration.HumanDuration(end.Sub(step.StartedAt.Time))
+		}
+		fmt.Fprintf(w, "  %s\t%t\t%s\t%s\t%s\n", step.Name, step.Ready, stepDuration, completed, step.Error)
+	}
+	return w.Flush()
+}
+
+// getPhase returns Stalled or Paused, the first step which is not ready, or Ready when all steps are
+func getPhase(jira appv1.Jira) string {
+	if jira.IsPaused() {
+		return appv1.ConditionPaused
+	}
+	if meta.IsStatusConditionTrue(jira.Status.Conditions, appv1.ConditionStalled) {
+		return appv1.ConditionStalled
+	}
+	if len(jira.Status.Steps) == 0 {
+		return "Pending"
+	}
+	for _, step := range jira.Status.Steps {
+		if !step.Ready {
+			return step.Name
+		}
+	}
+	return "Ready"
+}
+
+func age(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return duration.HumanDuration(time.Since(t))
+}
@@ -0,0 +1,158 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+	"github.com/atlassian-labs/jira-operator/metrics"
+	ec2 "github.com/crossplane-contrib/provider-aws/apis/ec2/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/structured-merge-diff/v4/fieldpath"
+)
+
+// fieldManager owns all fields the operator sets on resources it applies
+const fieldManager = "jira-operator"
+
+// apply creates or updates a resource with server-side apply, so that any manual change to the fields
+// the operator manages is reverted on every reconcile. A Warning event is emitted on Jira for each corrected drift,
+// and a Normal one when the resource changed because Jira spec did.
+// Generated credentials and jobs are not applied, as they are meant to be created once
+func (r *JiraReconciler) apply(ctx context.Context, jira *appv1.Jira, obj client.Object) (err error) {
+	logger := log.FromContext(ctx)
+	gvk, err := r.GroupVersionKindFor(obj)
+	if err != nil {
+		return err
+	}
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+	namespaced, err := r.IsObjectNamespaced(obj)
+	if err != nil {
+		return err
+	}
+	if !namespaced {
+		obj.SetNamespace("")
+	}
+
+	existing := obj.DeepCopyObject().(client.Object)
+	err = r.Get(ctx, client.ObjectKeyFromObject(obj), existing)
+	found := err == nil
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	if found {
+		preserveServerState(obj, existing)
+	}
+
+	err = r.Patch(ctx, obj, client.Apply, client.FieldOwner(fieldManager), client.ForceOwnership)
+	if err != nil {
+		return err
+	}
+
+	if !found {
+		r.Recorder.Eventf(jira, corev1.EventTypeNormal, "Created", "Created %s %s", gvk.Kind, obj.GetName())
+	} else if obj.GetResourceVersion() != existing.GetResourceVersion() {
+		if isDrifted(existing, obj) {
+			logger.Info("Corrected drift in " + gvk.Kind + " " + obj.GetName())
+			r.Recorder.Eventf(jira, corev1.EventTypeWarning, "DriftCorrected", "Corrected drift in %s %s", gvk.Kind, obj.GetName())
+		} else {
+			logger.Info("Updated " + gvk.Kind + " " + obj.GetName())
+			r.Recorder.Eventf(jira, corev1.EventTypeNormal, "Updated", "Updated %s %s", gvk.Kind, obj.GetName())
+		}
+	}
+	return nil
+}
+
+// isDrifted returns whether a resource changed by an apply had fields the operator applies set by another manager,
+// which then owned them. Otherwise the operator only applied a new desired state, e.g. after a spec change.
+// Status updates are not drift, they are recorded on their own subresource
+func isDrifted(existing client.Object, applied client.Object) bool {
+	appliedFields := fieldpath.NewSet()
+	for _, entry := range applied.GetManagedFields() {
+		if entry.Manager == fieldManager && entry.Operation == metav1.ManagedFieldsOperationApply && entry.FieldsV1 != nil {
+			_ = appliedFields.FromJSON(bytes.NewReader(entry.FieldsV1.Raw))
+		}
+	}
+	for _, entry := range existing.GetManagedFields() {
+		if entry.Manager == fieldManager || entry.Subresource != "" || entry.FieldsV1 == nil {
+			continue
+		}
+		otherFields := fieldpath.NewSet()
+		if otherFields.FromJSON(bytes.NewReader(entry.FieldsV1.Raw)) != nil {
+			continue
+		}
+		// only leaves are compared, maps such as labels are shared by all managers adding to them
+		if !appliedFields.Leaves().Intersection(otherFields.Leaves()).Empty() {
+			return true
+		}
+	}
+	return false
+}
+
+// create creates a resource which is left as is once it exists, such as jobs and generated credentials
+func (r *JiraReconciler) create(ctx context.Context, jira *appv1.Jira, obj client.Object) (err error) {
+	err = r.Create(ctx, obj)
+	if errors.IsAlreadyExists(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	gvk, err := r.GroupVersionKindFor(obj)
+	if err != nil {
+		return err
+	}
+	r.Recorder.Eventf(jira, corev1.EventTypeNormal, "Created", "Created %s %s", gvk.Kind, obj.GetName())
+	return nil
+}
+
+// retryFailedJob deletes a failed job together with its pods, and returns a transient error so that the step which
+// creates the job creates it again after backoff. Failed jobs do not stall reconciliation, as deleting them is the only fix
+// for a failure such as a database not reachable yet, and Jira spec does not change when they are deleted
+func (r *JiraReconciler) retryFailedJob(ctx context.Context, jira *appv1.Jira, job batchv1.Job, description string) (err error) {
+	metrics.IncJobFailures(jira.Name, job.Name)
+	r.Recorder.Event(jira, corev1.EventTypeWarning, "JobFailed", description+" job "+job.Name+" failed, retrying")
+	err = r.Delete(ctx, &job, client.PropagationPolicy(metav1.DeletePropagationBackground))
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return fmt.Errorf("%s job %s failed, it is recreated to retry", description, job.Name)
+}
+
+// preserveServerState copies to the desired state fields which the operator must not revert:
+// bound PV claim references, and volume sizes that have been expanded and cannot shrink
+func preserveServerState(desired client.Object, existing client.Object) {
+	switch desiredObj := desired.(type) {
+	case *corev1.PersistentVolume:
+		existingObj := existing.(*corev1.PersistentVolume)
+		// claim reference is atomic, binding uid would otherwise be removed
+		if desiredObj.Spec.ClaimRef != nil && existingObj.Spec.ClaimRef != nil &&
+			desiredObj.Spec.ClaimRef.Name == existingObj.Spec.ClaimRef.Name && desiredObj.Spec.ClaimRef.Namespace == existingObj.Spec.ClaimRef.Namespace {
+			desiredObj.Spec.ClaimRef = existingObj.Spec.ClaimRef.DeepCopy()
+		}
+		preserveLargerQuantity(desiredObj.Spec.Capacity, existingObj.Spec.Capacity)
+	case *corev1.PersistentVolumeClaim:
+		existingObj := existing.(*corev1.PersistentVolumeClaim)
+		preserveLargerQuantity(desiredObj.Spec.Resources.Requests, existingObj.Spec.Resources.Requests)
+	case *ec2.Volume:
+		existingObj := existing.(*ec2.Volume)
+		if desiredObj.Spec.ForProvider.Size != nil && existingObj.Spec.ForProvider.Size != nil &&
+			*existingObj.Spec.ForProvider.Size > *desiredObj.Spec.ForProvider.Size {
+			size := *existingObj.Spec.ForProvider.Size
+			desiredObj.Spec.ForProvider.Size = &size
+		}
+	}
+}
+
+func preserveLargerQuantity(desired corev1.ResourceList, existing corev1.ResourceList) {
+	existingStorage, ok := existing[corev1.ResourceStorage]
+	if !ok || desired == nil {
+		return
+	}
+	if desiredStorage, ok := desired[corev1.ResourceStorage]; ok && existingStorage.Cmp(desiredStorage) > 0 {
+		desired[corev1.ResourceStorage] = existingStorage
+	}
+}
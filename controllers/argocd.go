@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"context"
+	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+	"github.com/atlassian-labs/jira-operator/argocd"
+	"github.com/atlassian-labs/jira-operator/k8s"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// argoCDDelivery deploys Jira with an Argo CD ApplicationSet or Application
+type argoCDDelivery struct {
+	r *JiraReconciler
+}
+
+func (d *argoCDDelivery) name() string {
+	return "ArgoCD"
+}
+
+func (d *argoCDDelivery) deploy(ctx context.Context, jira *appv1.Jira) (err error) {
+	logger := log.FromContext(ctx)
+
+	// secrets and shared home are copied before Argo CD deploys pods which mount them
+	if !jira.Spec.ArgoCD.Destination.IsLocal() {
+		workloadClient, err := d.r.workloadClient(ctx, jira)
+		if err != nil {
+			return err
+		}
+		err = d.r.copyWorkloadResources(ctx, jira, workloadClient)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Argo CD dependencies conflict with other k8s deps in this project, see: https://github.com/argoproj/argo-cd/issues/14727
+	// as a result, rather than creating argo Application or Applicationset in Go, we will process a template and kubectl apply the resulting file
+	argoFilePath, err := argocd.ProcessApplicationTemplate(*jira)
+	if err != nil {
+		return err
+	}
+	args := []string{"apply", "-f", argoFilePath}
+	output, err := k8s.RunKubectl(args)
+	if err != nil {
+		return err
+	}
+	logger.Info(argocd.GetKind(*jira) + ": " + string(output))
+
+	// an ApplicationSet left from before switching to an Application is deleted without its application, which is adopted instead
+	if argocd.GetKind(*jira) == appv1.ArgoCDKindApplication {
+		args = []string{"delete", "applicationset/" + jira.Name, "-n", jira.Spec.ArgoCD.Namespace, "--cascade=orphan", "--ignore-not-found"}
+		output, err = k8s.RunKubectl(args)
+		if err != nil {
+			return err
+		}
+		if len(output) > 0 {
+			logger.Info("Applicationset: " + string(output))
+		}
+	}
+	return nil
+}
+
+func (d *argoCDDelivery) status(ctx context.Context, jira *appv1.Jira) (appStatus appv1.AppStatus, err error) {
+	return argocd.GetAppStatus(*jira)
+}
@@ -0,0 +1,496 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+	"github.com/atlassian-labs/jira-operator/backup"
+	"github.com/atlassian-labs/jira-operator/k8s"
+	"github.com/atlassian-labs/jira-operator/naming"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"time"
+)
+
+const defaultRollbackWindow = 24 * time.Hour
+
+// blueGreenStep deploys a new chart version or values revision to a green stack, which is a Jira owned by this one restored
+// from snapshots of the live stack, and switches the external-dns hostname to it once it is healthy. The previous stack
+// is kept for the rollback window, during which reverting spec to the previous release switches the hostname back
+type blueGreenStep struct {
+	r *JiraReconciler
+}
+
+func (s *blueGreenStep) Name() string {
+	return "BlueGreen"
+}
+
+func (s *blueGreenStep) Ensure(ctx context.Context, jira *appv1.Jira) (stepResult, error) {
+	r := s.r
+	if jira.Spec.Promotion.Enabled {
+		return stepResult{}, newSpecError("spec.promotion and spec.blueGreen cannot be enabled together")
+	}
+	if jira.Spec.SharedFS.GetType() == appv1.SharedFSTypeEfs {
+		return stepResult{}, newSpecError("blue/green deployment needs shared home on EBS or FSx, which can be restored from a snapshot")
+	}
+	rollbackWindow, err := getRollbackWindow(*jira)
+	if err != nil {
+		return stepResult{}, err
+	}
+	status := &jira.Status.BlueGreen
+	target := getSpecRelease(*jira)
+	err = r.updateStacks(ctx, jira)
+	if err != nil {
+		return stepResult{}, err
+	}
+
+	// the stack of Jira keeps the release it is first deployed with, later ones are deployed to green stacks
+	if jira.Status.Promotion.Release == (appv1.Release{}) {
+		jira.Status.Promotion.Release = target
+		status.LiveRelease = target
+		return stepDone(), r.Status().Update(context.TODO(), jira)
+	}
+
+	switch status.Phase {
+	case appv1.BlueGreenPhaseSnapshotting, appv1.BlueGreenPhaseDeploying:
+		if target == status.To {
+			if status.Phase == appv1.BlueGreenPhaseSnapshotting {
+				return r.snapshotLiveStack(ctx, jira)
+			}
+			return r.deployGreenStack(ctx, jira)
+		}
+		err = r.abortBlueGreen(ctx, jira, "Spec changed to "+target.String())
+		if err != nil {
+			return stepResult{}, err
+		}
+	case appv1.BlueGreenPhaseSwitched:
+		if target == status.From {
+			return stepDone(), r.rollBackBlueGreen(ctx, jira)
+		}
+		rollbackUntil := status.SwitchedAt.Add(rollbackWindow)
+		if time.Now().Before(rollbackUntil) {
+			if target != status.LiveRelease {
+				return stepWaiting("RollbackWindow", fmt.Sprintf("Waiting until %s to deploy %s, previous stack %s is kept until then",
+					rollbackUntil.UTC().Format(time.RFC3339), target.String(), status.Previous), time.Until(rollbackUntil)), nil
+			}
+			return stepDone(), nil
+		}
+		err = r.completeBlueGreen(ctx, jira)
+		if err != nil {
+			return stepResult{}, err
+		}
+	}
+
+	if target == status.LiveRelease {
+		return stepDone(), nil
+	}
+	return r.startBlueGreen(ctx, jira)
+}
+
+func (s *blueGreenStep) Ready(ctx context.Context, jira *appv1.Jira) (stepResult, error) {
+	status := jira.Status.BlueGreen
+	if status.Phase == appv1.BlueGreenPhaseSwitched {
+		return stepReady("RollbackWindow", fmt.Sprintf("%s serves %s with %s, previous stack %s is kept for rollback",
+			getLiveStack(*jira), jira.Spec.Hostname, status.LiveRelease.String(), status.Previous)), nil
+	}
+	return stepReady("Live", getLiveStack(*jira)+" serves "+jira.Spec.Hostname+" with "+status.LiveRelease.String()), nil
+}
+
+// getLiveStack returns the name of the Jira serving the hostname
+func getLiveStack(jira appv1.Jira) string {
+	if jira.Status.BlueGreen.Live == "" {
+		return jira.Name
+	}
+	return jira.Status.BlueGreen.Live
+}
+
+// getStack returns a stack of Jira, which is either Jira itself or a Jira it owns
+func (r *JiraReconciler) getStack(ctx context.Context, jira *appv1.Jira, name string) (stack *appv1.Jira, err error) {
+	if name == jira.Name {
+		return jira, nil
+	}
+	stack = &appv1.Jira{}
+	err = r.Get(ctx, client.ObjectKey{Name: name}, stack)
+	return stack, err
+}
+
+// startBlueGreen starts deploying the release of Jira spec to a new green stack
+func (r *JiraReconciler) startBlueGreen(ctx context.Context, jira *appv1.Jira) (stepResult, error) {
+	logger := log.FromContext(ctx)
+	status := &jira.Status.BlueGreen
+	now := metav1.Now()
+	status.Phase = appv1.BlueGreenPhaseSnapshotting
+	status.Green = naming.NextBlueGreenStack(*jira, now.Time)
+	status.From = status.LiveRelease
+	status.To = getSpecRelease(*jira)
+	status.DatabaseSnapshotId = ""
+	status.SharedHomeSnapshotId = ""
+	status.StartedAt = &now
+	status.Message = ""
+	logger.Info("Deploying " + status.To.String() + " to green stack " + status.Green)
+	r.Recorder.Event(jira, corev1.EventTypeNormal, "BlueGreenStarted", "Deploying "+status.To.String()+" to green stack "+status.Green)
+	return r.snapshotLiveStack(ctx, jira)
+}
+
+// snapshotLiveStack snapshots database and shared home of the live stack, which the green stack is restored from.
+// The live stack is put in maintenance until the snapshots are taken, so that both are taken from the same state
+func (r *JiraReconciler) snapshotLiveStack(ctx context.Context, jira *appv1.Jira) (stepResult, error) {
+	status := &jira.Status.BlueGreen
+	live, err := r.getStack(ctx, jira, getLiveStack(*jira))
+	if err != nil {
+		return stepResult{}, err
+	}
+	if status.DatabaseSnapshotId == "" || status.SharedHomeSnapshotId == "" {
+		scaledDown, err := r.freezeLiveStack(ctx, jira, live)
+		if err != nil {
+			return stepResult{}, err
+		}
+		if !scaledDown {
+			return stepWaiting("FreezingLiveStack", "Waiting for "+live.Name+" to be scaled down to snapshot it", 30*time.Second), nil
+		}
+	}
+	databaseSnapshotId, sharedHomeSnapshotId := status.DatabaseSnapshotId, status.SharedHomeSnapshotId
+	available, err := r.takeSnapshots(ctx, live, &status.DatabaseSnapshotId, &status.SharedHomeSnapshotId)
+	if errors.Is(err, backup.ErrSnapshotFailed) {
+		abortErr := r.abortBlueGreen(ctx, jira, err.Error())
+		if abortErr != nil {
+			return stepResult{}, abortErr
+		}
+		return stepResult{}, newPermanentError("blue/green deployment of %s failed: %w", status.To.String(), err)
+	}
+	// snapshot IDs are recorded even when taking another snapshot failed, so that a snapshot is not taken twice
+	if status.DatabaseSnapshotId != databaseSnapshotId || status.SharedHomeSnapshotId != sharedHomeSnapshotId {
+		r.Recorder.Event(jira, corev1.EventTypeNormal, "CreatingSnapshots", "Creating snapshots of "+live.Name+" to restore green stack "+status.Green+" from")
+	}
+	statusErr := r.Status().Update(context.TODO(), jira)
+	if statusErr != nil {
+		return stepResult{}, statusErr
+	}
+	if err != nil {
+		return stepResult{}, err
+	}
+	// snapshots are consistent from the time they are created, the live stack does not wait for them to be available
+	if status.DatabaseSnapshotId != "" && status.SharedHomeSnapshotId != "" {
+		err = r.unfreezeLiveStack(ctx, jira)
+		if err != nil {
+			return stepResult{}, err
+		}
+	}
+	if !available {
+		return stepWaiting("CreatingSnapshots", "Waiting for snapshots of "+live.Name+" to restore green stack "+status.Green+" from", time.Minute), nil
+	}
+
+	err = r.createGreenStack(ctx, jira)
+	if err != nil {
+		return stepResult{}, err
+	}
+	status.Phase = appv1.BlueGreenPhaseDeploying
+	err = r.Status().Update(context.TODO(), jira)
+	if err != nil {
+		return stepResult{}, err
+	}
+	return stepWaiting("DeployingGreenStack", "Waiting for green stack "+status.Green+" to be healthy with "+status.To.String(), time.Minute), nil
+}
+
+// freezeLiveStack puts the live stack in maintenance, unless it already is, and returns whether it is scaled down
+func (r *JiraReconciler) freezeLiveStack(ctx context.Context, jira *appv1.Jira, live *appv1.Jira) (scaledDown bool, err error) {
+	logger := log.FromContext(ctx)
+	status := &jira.Status.BlueGreen
+	if !live.Spec.Maintenance {
+		logger.Info("Putting " + live.Name + " in maintenance to snapshot it")
+		r.Recorder.Event(jira, corev1.EventTypeNormal, "FreezingLiveStack", "Putting "+live.Name+" in maintenance to snapshot it")
+		status.Frozen = true
+		err = r.Status().Update(context.TODO(), jira)
+		if err != nil {
+			return false, err
+		}
+		err = r.setMaintenance(ctx, live, true)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	workloadClient, err := r.workloadClient(ctx, live)
+	if err != nil {
+		return false, err
+	}
+	var statefulSet appsv1.StatefulSet
+	err = workloadClient.Get(context.TODO(), client.ObjectKey{Name: naming.JiraStatefulSet(*live), Namespace: k8s.GetNamespaceName(*live)}, &statefulSet)
+	if apierrors.IsNotFound(err) {
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+	return statefulSet.Spec.Replicas != nil && *statefulSet.Spec.Replicas == 0 && statefulSet.Status.Replicas == 0, nil
+}
+
+// unfreezeLiveStack takes the live stack out of the maintenance blue/green deployment put it in
+func (r *JiraReconciler) unfreezeLiveStack(ctx context.Context, jira *appv1.Jira) (err error) {
+	status := &jira.Status.BlueGreen
+	if !status.Frozen {
+		return nil
+	}
+	live, err := r.getStack(ctx, jira, getLiveStack(*jira))
+	if err != nil {
+		return err
+	}
+	err = r.setMaintenance(ctx, live, false)
+	if err != nil {
+		return err
+	}
+	r.Recorder.Event(jira, corev1.EventTypeNormal, "UnfreezingLiveStack", "Taking "+live.Name+" out of maintenance")
+	status.Frozen = false
+	return r.Status().Update(context.TODO(), jira)
+}
+
+// setMaintenance sets spec.maintenance of a stack
+func (r *JiraReconciler) setMaintenance(ctx context.Context, stack *appv1.Jira, maintenance bool) (err error) {
+	if stack.Spec.Maintenance == maintenance {
+		return nil
+	}
+	patch := client.MergeFrom(stack.DeepCopy())
+	stack.Spec.Maintenance = maintenance
+	return r.Patch(ctx, stack, patch)
+}
+
+// createGreenStack creates the Jira of the green stack with the spec of this Jira, restored from snapshots of the live stack.
+// It does not serve the hostname until DNS is switched to it
+func (r *JiraReconciler) createGreenStack(ctx context.Context, jira *appv1.Jira) (err error) {
+	status := jira.Status.BlueGreen
+	green := appv1.Jira{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            status.Green,
+			Labels:          map[string]string{appv1.BlueGreenParentLabel: jira.Name},
+			Annotations:     map[string]string{appv1.ExternalDNSDisabledAnnotation: "true"},
+			OwnerReferences: k8s.GetOwnerReferences(*jira),
+		},
+		Spec: *jira.Spec.DeepCopy(),
+	}
+	err = backup.SetSnapshots(&green, status.DatabaseSnapshotId, status.SharedHomeSnapshotId)
+	if err != nil {
+		return newPermanentError("%w", err)
+	}
+	setStackSpec(*jira, &green)
+	err = r.Create(ctx, &green)
+	if apierrors.IsAlreadyExists(err) {
+		return nil
+	}
+	return err
+}
+
+// updateStacks reconciles the spec of the stacks Jira owns with its own, so that changes to Jira spec other than its
+// release are applied to the live stack and to the stack being deployed
+func (r *JiraReconciler) updateStacks(ctx context.Context, jira *appv1.Jira) (err error) {
+	status := jira.Status.BlueGreen
+	for _, name := range []string{status.Live, status.Green, status.Previous} {
+		if name == "" || name == jira.Name {
+			continue
+		}
+		stack := &appv1.Jira{}
+		err = r.Get(ctx, client.ObjectKey{Name: name}, stack)
+		if apierrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		existing := stack.DeepCopy()
+		setStackSpec(*jira, stack)
+		if equality.Semantic.DeepEqual(existing.Spec, stack.Spec) {
+			continue
+		}
+		log.FromContext(ctx).Info("Updating spec of stack " + name)
+		err = r.Patch(ctx, stack, client.MergeFrom(existing))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setStackSpec sets the spec of a stack to the one of Jira, except for its release and the snapshots it was restored from.
+// The live stack stays in maintenance while it is frozen to be snapshotted
+func setStackSpec(jira appv1.Jira, stack *appv1.Jira) {
+	spec := jira.Spec.DeepCopy()
+	spec.BlueGreen = appv1.BlueGreenSpec{}
+	spec.ArgoCD.HelmChart.Version = stack.Spec.ArgoCD.HelmChart.Version
+	spec.ArgoCD.HelmValues.GitRevision = stack.Spec.ArgoCD.HelmValues.GitRevision
+	spec.Database.SnapshotID = stack.Spec.Database.SnapshotID
+	spec.SharedFS.Type = stack.Spec.SharedFS.Type
+	spec.SharedFS.Ebs.SnapshotId = stack.Spec.SharedFS.Ebs.SnapshotId
+	spec.SharedFS.Fsx.SnapshotId = stack.Spec.SharedFS.Fsx.SnapshotId
+	// a created namespace is named after the stack rather than shared with the live one
+	if spec.TargetNamespace.Mode == "" || spec.TargetNamespace.Mode == appv1.NamespaceModeCreate {
+		spec.TargetNamespace.Name = ""
+	}
+	spec.Maintenance = jira.Spec.Maintenance || (jira.Status.BlueGreen.Frozen && stack.Name == getLiveStack(jira))
+	stack.Spec = *spec
+}
+
+// deployGreenStack waits for the green stack to be ready and healthy, and then switches DNS to it
+func (r *JiraReconciler) deployGreenStack(ctx context.Context, jira *appv1.Jira) (stepResult, error) {
+	logger := log.FromContext(ctx)
+	status := &jira.Status.BlueGreen
+	green := appv1.Jira{}
+	err := r.Get(ctx, client.ObjectKey{Name: status.Green}, &green)
+	if apierrors.IsNotFound(err) {
+		err = r.createGreenStack(ctx, jira)
+		return stepWaiting("DeployingGreenStack", "Creating green stack "+status.Green, time.Minute), err
+	} else if err != nil {
+		return stepResult{}, err
+	}
+	if !isStackReady(green) {
+		return stepWaiting("DeployingGreenStack", "Waiting for green stack "+status.Green+" to be healthy with "+status.To.String(), time.Minute), nil
+	}
+
+	// the live stack stops serving the hostname before the green one does, so that external-dns never sees both
+	live := getLiveStack(*jira)
+	err = r.setServesHostname(ctx, jira, live, false)
+	if err != nil {
+		return stepResult{}, err
+	}
+	err = r.setServesHostname(ctx, jira, status.Green, true)
+	if err != nil {
+		return stepResult{}, err
+	}
+	logger.Info("Switched " + jira.Spec.Hostname + " from " + live + " to " + status.Green)
+	r.Recorder.Event(jira, corev1.EventTypeNormal, "DNSSwitched", "Switched "+jira.Spec.Hostname+" from "+live+" to "+status.Green)
+	now := metav1.Now()
+	status.Previous = live
+	status.Live = status.Green
+	status.Green = ""
+	status.LiveRelease = status.To
+	status.Phase = appv1.BlueGreenPhaseSwitched
+	status.SwitchedAt = &now
+	status.Message = "Switched " + jira.Spec.Hostname + " from " + live + " to " + status.Live
+	return stepDone(), r.Status().Update(context.TODO(), jira)
+}
+
+// rollBackBlueGreen switches DNS back to the previous stack, and deletes the stack which replaced it
+func (r *JiraReconciler) rollBackBlueGreen(ctx context.Context, jira *appv1.Jira) (err error) {
+	logger := log.FromContext(ctx)
+	status := &jira.Status.BlueGreen
+	current := getLiveStack(*jira)
+	err = r.setServesHostname(ctx, jira, current, false)
+	if err != nil {
+		return err
+	}
+	err = r.setServesHostname(ctx, jira, status.Previous, true)
+	if err != nil {
+		return err
+	}
+	err = r.deleteStack(ctx, jira, current)
+	if err != nil {
+		return err
+	}
+	logger.Info("Rolled " + jira.Spec.Hostname + " back from " + current + " to " + status.Previous)
+	r.Recorder.Event(jira, corev1.EventTypeWarning, "BlueGreenRolledBack", "Switched "+jira.Spec.Hostname+" back from "+current+" to "+status.Previous)
+	status.Live = status.Previous
+	status.LiveRelease = status.From
+	status.Previous = ""
+	status.Phase = appv1.BlueGreenPhaseRolledBack
+	status.Message = "Rolled back from " + current + " with " + status.To.String()
+	return r.Status().Update(context.TODO(), jira)
+}
+
+// completeBlueGreen tears down the previous stack once the rollback window is over. The stack of Jira itself cannot be
+// deleted without Jira, so it is scaled down instead
+func (r *JiraReconciler) completeBlueGreen(ctx context.Context, jira *appv1.Jira) (err error) {
+	logger := log.FromContext(ctx)
+	status := &jira.Status.BlueGreen
+	if status.Previous == jira.Name {
+		status.Standby = true
+	} else {
+		err = r.deleteStack(ctx, jira, status.Previous)
+		if err != nil {
+			return err
+		}
+	}
+	logger.Info("Rollback window is over, tearing down " + status.Previous)
+	r.Recorder.Event(jira, corev1.EventTypeNormal, "BlueGreenCompleted", "Rollback window is over, tearing down "+status.Previous)
+	status.Message = "Tore down " + status.Previous + " after the rollback window"
+	status.Previous = ""
+	status.Phase = appv1.BlueGreenPhaseCompleted
+	return r.Status().Update(context.TODO(), jira)
+}
+
+// abortBlueGreen deletes the green stack being deployed, the live stack keeps serving the hostname
+func (r *JiraReconciler) abortBlueGreen(ctx context.Context, jira *appv1.Jira, message string) (err error) {
+	logger := log.FromContext(ctx)
+	status := &jira.Status.BlueGreen
+	err = r.unfreezeLiveStack(ctx, jira)
+	if err != nil {
+		return err
+	}
+	err = r.deleteStack(ctx, jira, status.Green)
+	if err != nil {
+		return err
+	}
+	logger.Info("Aborting deployment of green stack " + status.Green + ": " + message)
+	r.Recorder.Event(jira, corev1.EventTypeWarning, "BlueGreenAborted", "Aborted deployment of green stack "+status.Green+": "+message)
+	status.Green = ""
+	status.Phase = appv1.BlueGreenPhaseAborted
+	status.Message = message
+	return r.Status().Update(context.TODO(), jira)
+}
+
+// setServesHostname removes or sets ExternalDNSDisabledAnnotation on a stack, which renders its ingress with or without
+// the external-dns hostname
+func (r *JiraReconciler) setServesHostname(ctx context.Context, jira *appv1.Jira, name string, serves bool) (err error) {
+	stack, err := r.getStack(ctx, jira, name)
+	if err != nil {
+		return err
+	}
+	patch := client.MergeFrom(stack.DeepCopy())
+	if serves {
+		delete(stack.Annotations, appv1.ExternalDNSDisabledAnnotation)
+	} else {
+		if stack.Annotations == nil {
+			stack.Annotations = map[string]string{}
+		}
+		stack.Annotations[appv1.ExternalDNSDisabledAnnotation] = "true"
+	}
+	return r.Patch(ctx, stack, patch)
+}
+
+// deleteStack deletes a stack owned by Jira together with its database and shared home, unless it retains them on delete
+func (r *JiraReconciler) deleteStack(ctx context.Context, jira *appv1.Jira, name string) (err error) {
+	if name == "" || name == jira.Name {
+		return nil
+	}
+	err = r.Delete(ctx, &appv1.Jira{ObjectMeta: metav1.ObjectMeta{Name: name}})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// isStackReady returns whether all steps of a stack are ready and its application is synced and healthy
+func isStackReady(stack appv1.Jira) bool {
+	if len(stack.Status.Steps) == 0 || meta.IsStatusConditionTrue(stack.Status.Conditions, appv1.ConditionStalled) {
+		return false
+	}
+	for _, stepStatus := range stack.Status.Steps {
+		if !stepStatus.Ready {
+			return false
+		}
+	}
+	return stack.Status.AppStatus.Sync == syncStatusSynced && stack.Status.AppStatus.Health == healthStatusHealthy
+}
+
+func getRollbackWindow(jira appv1.Jira) (time.Duration, error) {
+	if jira.Spec.BlueGreen.RollbackWindow == "" {
+		return defaultRollbackWindow, nil
+	}
+	rollbackWindow, err := time.ParseDuration(jira.Spec.BlueGreen.RollbackWindow)
+	if err != nil {
+		return 0, newSpecError("invalid spec.blueGreen.rollbackWindow: %w", err)
+	}
+	return rollbackWindow, nil
+}
@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func newTestStack(name string, chartVersion string, snapshotId string) *appv1.Jira {
+	stack := &appv1.Jira{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	stack.Spec.ArgoCD.HelmChart.Version = chartVersion
+	stack.Spec.Database.SnapshotID = snapshotId
+	stack.Spec.SharedFS.Type = appv1.SharedFSTypeEbs
+	stack.Spec.SharedFS.Ebs.SnapshotId = snapshotId
+	return stack
+}
+
+func TestUpdateStacks(t *testing.T) {
+	tests := []struct {
+		name            string
+		frozen          bool
+		maintenance     bool
+		wantMaintenance map[string]bool
+	}{
+		{"spec is propagated", false, false, map[string]bool{"jira-live": false, "jira-green": false}},
+		{"frozen live stack stays in maintenance", true, false, map[string]bool{"jira-live": true, "jira-green": false}},
+		{"maintenance of Jira is propagated", false, true, map[string]bool{"jira-live": true, "jira-green": true}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			jira := newTestStack("jira", "1.2.0", "")
+			jira.UID = "c0ffee"
+			jira.Spec.Jira.Replicas = pointer.Int32(3)
+			jira.Spec.BlueGreen.Enabled = true
+			jira.Spec.Maintenance = test.maintenance
+			jira.Status.BlueGreen = appv1.BlueGreenStatus{Live: "jira-live", Green: "jira-green", Frozen: test.frozen}
+			live := newTestStack("jira-live", "1.0.0", "snap-live")
+			green := newTestStack("jira-green", "1.2.0", "snap-green")
+			r, jira := newTestReconciler(t, jira, live, green)
+
+			err := r.updateStacks(context.TODO(), jira)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for name, snapshotId := range map[string]string{"jira-live": "snap-live", "jira-green": "snap-green"} {
+				stack := &appv1.Jira{}
+				if err := r.Get(context.TODO(), client.ObjectKey{Name: name}, stack); err != nil {
+					t.Fatal(err)
+				}
+				if stack.Spec.Jira.Replicas == nil || *stack.Spec.Jira.Replicas != 3 {
+					t.Errorf("%s replicas = %v, want 3", name, stack.Spec.Jira.Replicas)
+				}
+				if stack.Spec.BlueGreen.Enabled {
+					t.Errorf("%s has blue/green deployment enabled", name)
+				}
+				if stack.Spec.Database.SnapshotID != snapshotId || stack.Spec.SharedFS.Ebs.SnapshotId != snapshotId {
+					t.Errorf("%s snapshots = %s and %s, want %s", name, stack.Spec.Database.SnapshotID, stack.Spec.SharedFS.Ebs.SnapshotId, snapshotId)
+				}
+				if stack.Spec.Maintenance != test.wantMaintenance[name] {
+					t.Errorf("%s maintenance = %t, want %t", name, stack.Spec.Maintenance, test.wantMaintenance[name])
+				}
+			}
+			stack := &appv1.Jira{}
+			if err := r.Get(context.TODO(), client.ObjectKey{Name: "jira-live"}, stack); err != nil {
+				t.Fatal(err)
+			}
+			if stack.Spec.ArgoCD.HelmChart.Version != "1.0.0" {
+				t.Errorf("live stack chart version = %s, want its own release 1.0.0", stack.Spec.ArgoCD.HelmChart.Version)
+			}
+		})
+	}
+}
+
+func TestGetRollbackWindow(t *testing.T) {
+	tests := []struct {
+		rollbackWindow string
+		want           time.Duration
+		wantKind       errorKind
+	}{
+		{"", defaultRollbackWindow, ""},
+		{"2h", 2 * time.Hour, ""},
+		{"two hours", 0, errorKindSpec},
+	}
+	for _, test := range tests {
+		t.Run(test.rollbackWindow, func(t *testing.T) {
+			jira := appv1.Jira{}
+			jira.Spec.BlueGreen.RollbackWindow = test.rollbackWindow
+			got, err := getRollbackWindow(jira)
+			if test.wantKind != "" {
+				if err == nil || classifyError(err) != test.wantKind {
+					t.Errorf("getRollbackWindow() error = %v, want %s", err, test.wantKind)
+				}
+				return
+			}
+			if err != nil || got != test.want {
+				t.Errorf("getRollbackWindow() = %s, %v, want %s", got, err, test.want)
+			}
+		})
+	}
+}
+
+func TestIsStackReady(t *testing.T) {
+	readyStatus := func() appv1.JiraStatus {
+		status := appv1.JiraStatus{Steps: []appv1.StepStatus{{Name: "Namespace", Ready: true}, {Name: "Delivery", Ready: true}}}
+		status.AppStatus.Sync = syncStatusSynced
+		status.AppStatus.Health = healthStatusHealthy
+		return status
+	}
+	tests := []struct {
+		name   string
+		modify func(*appv1.JiraStatus)
+		want   bool
+	}{
+		{"ready and healthy", func(*appv1.JiraStatus) {}, true},
+		{"no steps yet", func(status *appv1.JiraStatus) { status.Steps = nil }, false},
+		{"step not ready", func(status *appv1.JiraStatus) { status.Steps[1].Ready = false }, false},
+		{"not healthy", func(status *appv1.JiraStatus) { status.AppStatus.Health = "Progressing" }, false},
+		{"stalled", func(status *appv1.JiraStatus) {
+			status.Conditions = []metav1.Condition{{Type: appv1.ConditionStalled, Status: metav1.ConditionTrue}}
+		}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stack := appv1.Jira{Status: readyStatus()}
+			test.modify(&stack.Status)
+			if got := isStackReady(stack); got != test.want {
+				t.Errorf("isStackReady() = %t, want %t", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+	"github.com/atlassian-labs/jira-operator/k8s"
+	"github.com/atlassian-labs/jira-operator/naming"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// argoCDClusterSecretLabel selects secrets in which Argo CD stores clusters it deploys to
+const argoCDClusterSecretLabel = "argocd.argoproj.io/secret-type"
+
+// argoCDClusterConfig is the connection config of a cluster registered in Argo CD, see
+// https://argo-cd.readthedocs.io/en/stable/operator-manual/declarative-setup/#clusters
+type argoCDClusterConfig struct {
+	BearerToken     string `json:"bearerToken"`
+	TLSClientConfig struct {
+		Insecure   bool   `json:"insecure"`
+		ServerName string `json:"serverName"`
+		CAData     []byte `json:"caData"`
+		CertData   []byte `json:"certData"`
+		KeyData    []byte `json:"keyData"`
+	} `json:"tlsClientConfig"`
+}
+
+// workloadClient returns a client of the cluster Argo CD deploys Jira to, which is read from the Argo CD cluster secret
+// when Jira is not deployed to the cluster the operator runs on. Only bearer token and client certificate auth are supported
+func (r *JiraReconciler) workloadClient(ctx context.Context, jira *appv1.Jira) (client.Client, error) {
+	destination := jira.Spec.ArgoCD.Destination
+	if destination.IsLocal() {
+		return r.Client, nil
+	}
+	if destination.Name != "" && destination.Server != "" {
+		return nil, newSpecError("only one of spec.argocd.destination name and server can be set")
+	}
+
+	var clusterSecrets corev1.SecretList
+	err := r.List(ctx, &clusterSecrets, client.InNamespace(jira.Spec.ArgoCD.Namespace), client.MatchingLabels{argoCDClusterSecretLabel: "cluster"})
+	if err != nil {
+		return nil, err
+	}
+	for _, clusterSecret := range clusterSecrets.Items {
+		if (destination.Name != "" && string(clusterSecret.Data["name"]) != destination.Name) ||
+			(destination.Server != "" && string(clusterSecret.Data["server"]) != destination.Server) {
+			continue
+		}
+		var config argoCDClusterConfig
+		err = json.Unmarshal(clusterSecret.Data["config"], &config)
+		if err != nil {
+			return nil, newSpecError("invalid config of Argo CD cluster secret %s: %w", clusterSecret.Name, err)
+		}
+		if config.BearerToken == "" && config.TLSClientConfig.CertData == nil {
+			return nil, newSpecError("Argo CD cluster secret %s has neither bearer token nor client certificate", clusterSecret.Name)
+		}
+		restConfig := &rest.Config{
+			Host:        string(clusterSecret.Data["server"]),
+			BearerToken: config.BearerToken,
+			TLSClientConfig: rest.TLSClientConfig{
+				Insecure:   config.TLSClientConfig.Insecure,
+				ServerName: config.TLSClientConfig.ServerName,
+				CAData:     config.TLSClientConfig.CAData,
+				CertData:   config.TLSClientConfig.CertData,
+				KeyData:    config.TLSClientConfig.KeyData,
+			},
+		}
+		return client.New(restConfig, client.Options{Scheme: r.Scheme})
+	}
+	return nil, newWaitingError("cluster %s%s is not registered in Argo CD", destination.Name, destination.Server)
+}
+
+// copyWorkloadResources copies secrets and the shared home volume Jira pods mount to the namespace Jira is deployed to
+// on a remote cluster. Copies are not owned by Jira, as owner references cannot point to another cluster, and are kept
+// when Jira is deleted. Only shared home on EFS is mounted by a CSI driver of the remote cluster, shared home on EBS
+// is served by an NFS server on the cluster of the operator and FSx volumes are provisioned for its CSI driver
+func (r *JiraReconciler) copyWorkloadResources(ctx context.Context, jira *appv1.Jira, workloadClient client.Client) (err error) {
+	logger := log.FromContext(ctx)
+	namespace := k8s.GetNamespaceName(*jira)
+	if jira.Spec.SharedFS.GetType() != appv1.SharedFSTypeEfs {
+		return newSpecError("shared home on %s cannot be mounted from destination cluster %s%s, only EFS can",
+			jira.Spec.SharedFS.GetType(), jira.Spec.ArgoCD.Destination.Name, jira.Spec.ArgoCD.Destination.Server)
+	}
+
+	remoteNamespace := k8s.GetNamespace(*jira)
+	remoteNamespace.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Namespace"}
+	remoteNamespace.OwnerReferences = nil
+	err = workloadClient.Patch(ctx, &remoteNamespace, client.Apply, client.FieldOwner(fieldManager), client.ForceOwnership)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range []string{naming.DatabaseSecret(*jira)} {
+		var secret corev1.Secret
+		err = r.Get(ctx, client.ObjectKey{Name: name, Namespace: namespace}, &secret)
+		if err != nil {
+			return err
+		}
+		remoteSecret := &corev1.Secret{
+			TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      secret.Name,
+				Namespace: namespace,
+				Labels:    secret.Labels,
+			},
+			Type: secret.Type,
+			Data: secret.Data,
+		}
+		err = workloadClient.Patch(ctx, remoteSecret, client.Apply, client.FieldOwner(fieldManager), client.ForceOwnership)
+		if err != nil {
+			return err
+		}
+		logger.Info("Copied secret " + name + " to destination cluster")
+	}
+
+	// the claim is bound to a volume of the same name on the remote cluster, which mounts the same EFS access point
+	var pv corev1.PersistentVolume
+	err = r.Get(ctx, client.ObjectKey{Name: naming.SharedHomePersistentVolume(*jira)}, &pv)
+	if err != nil {
+		return err
+	}
+	remotePv := &corev1.PersistentVolume{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "PersistentVolume"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   pv.Name,
+			Labels: pv.Labels,
+		},
+		Spec: *pv.Spec.DeepCopy(),
+	}
+	remotePv.Spec.ClaimRef = &corev1.ObjectReference{Kind: "PersistentVolumeClaim", Namespace: namespace, Name: naming.SharedHomeClaim(*jira)}
+	err = workloadClient.Patch(ctx, remotePv, client.Apply, client.FieldOwner(fieldManager), client.ForceOwnership)
+	if err != nil {
+		return err
+	}
+	var pvc corev1.PersistentVolumeClaim
+	err = r.Get(ctx, client.ObjectKey{Name: naming.SharedHomeClaim(*jira), Namespace: namespace}, &pvc)
+	if err != nil {
+		return err
+	}
+	remotePvc := &corev1.PersistentVolumeClaim{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "PersistentVolumeClaim"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pvc.Name,
+			Namespace: namespace,
+			Labels:    pvc.Labels,
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      pvc.Spec.AccessModes,
+			Resources:        pvc.Spec.Resources,
+			StorageClassName: pvc.Spec.StorageClassName,
+			VolumeName:       pv.Name,
+		},
+	}
+	err = workloadClient.Patch(ctx, remotePvc, client.Apply, client.FieldOwner(fieldManager), client.ForceOwnership)
+	if err != nil {
+		return err
+	}
+	logger.Info("Copied shared home volume " + pv.Name + " to destination cluster")
+	return nil
+}
@@ -0,0 +1,25 @@
+package controllers
+
+import (
+	"context"
+	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// setCondition sets a status condition on Jira custom resource, and updates the status only when the condition changed
+func (r *JiraReconciler) setCondition(jira *appv1.Jira, conditionType string, status metav1.ConditionStatus, reason string, message string) (err error) {
+	existingCondition := meta.FindStatusCondition(jira.Status.Conditions, conditionType)
+	if existingCondition != nil && existingCondition.Status == status && existingCondition.Reason == reason &&
+		existingCondition.Message == message && existingCondition.ObservedGeneration == jira.Generation {
+		return nil
+	}
+	meta.SetStatusCondition(&jira.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: jira.Generation,
+	})
+	return r.Status().Update(context.TODO(), jira)
+}
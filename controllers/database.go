@@ -0,0 +1,204 @@
+package controllers
+
+import (
+	"context"
+	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+	"github.com/atlassian-labs/jira-operator/crossplane"
+	"github.com/atlassian-labs/jira-operator/k8s"
+	"github.com/atlassian-labs/jira-operator/metrics"
+	"github.com/atlassian-labs/jira-operator/naming"
+	database "github.com/crossplane-contrib/provider-aws/apis/database/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"strconv"
+	"time"
+)
+
+// databaseStep creates RDS instance with its subnet and parameter groups, and the database secret holding its root password
+type databaseStep struct {
+	r *JiraReconciler
+}
+
+func (s *databaseStep) Name() string {
+	return "Database"
+}
+
+func (s *databaseStep) Ensure(ctx context.Context, jira *appv1.Jira) (stepResult, error) {
+	r := s.r
+	namespace := k8s.GetNamespaceName(*jira)
+
+	// create DBParameterGroup
+	dbParameterGroup := crossplane.GetDbParameterGroup(*jira)
+	err := r.apply(ctx, jira, &dbParameterGroup)
+	if err != nil {
+		return stepResult{}, err
+	}
+
+	// create DBSubnetGroup
+	dbSubnetGroup := crossplane.GetDbSubnetGroup(*jira)
+	err = r.apply(ctx, jira, &dbSubnetGroup)
+	if err != nil {
+		return stepResult{}, err
+	}
+
+	// create database secret which crossplane, liquibase and Jira will use
+	rdsSecret := k8s.GetRdsSecret(*jira, "replaceme", namespace)
+	err = r.create(ctx, jira, &rdsSecret)
+	if err != nil {
+		return stepResult{}, err
+	}
+
+	// create RDS instance
+	rdsInstance := crossplane.GetRdsInstance(*jira, dbSubnetGroup, dbParameterGroup, namespace)
+	err = r.apply(ctx, jira, &rdsInstance)
+	if err != nil {
+		return stepResult{}, err
+	}
+	return stepDone(), nil
+}
+
+func (s *databaseStep) Ready(ctx context.Context, jira *appv1.Jira) (stepResult, error) {
+	r := s.r
+	logger := log.FromContext(ctx)
+	rdsInstance := database.RDSInstance{}
+	rdsObjKey := client.ObjectKey{
+		Name: naming.ClusterScoped(*jira),
+	}
+
+	// get RDS status
+	rdsStatus, err := r.getRdsStatus(rdsInstance, rdsObjKey)
+	if err != nil {
+		return stepResult{}, err
+	}
+	metrics.SetRdsStatus(jira.Name, rdsObjKey.Name, rdsStatus)
+
+	// get current RDS status from custom resource and update it if it differs from the one in crossplane resource status
+	currentCRStatus := jira.Status.RDS.Status
+	if currentCRStatus != rdsStatus {
+		jira.Status.RDS.Status = rdsStatus
+		r.Recorder.Event(jira, corev1.EventTypeNormal, "RdsStatusChanged", "RDS "+rdsObjKey.Name+" status changed to "+rdsStatus)
+		err = r.Status().Update(context.TODO(), jira)
+		logger.Info("Updating RDS status to: " + rdsStatus)
+		if err != nil {
+			return stepResult{}, err
+		}
+	}
+
+	// RDS in one of these states will not become available without manual intervention
+	switch rdsStatus {
+	case "failed", "incompatible-restore", "incompatible-parameters", "incompatible-network", "storage-full":
+		return stepResult{}, newPermanentError("RDS %s is in %s state", rdsObjKey.Name, rdsStatus)
+	}
+
+	// to proceed RDS status must be available, let's check again in 30 seconds
+	if rdsStatus != "available" {
+		return stepWaiting("RdsNotAvailable", "Waiting for RDS available status: "+rdsObjKey.Name, 30*time.Second), nil
+	}
+
+	// get RDS hostname and update custom resource status with it
+	rdsHostname, err := r.getRdsEndpoint(rdsInstance, rdsObjKey)
+	if err != nil {
+		return stepResult{}, err
+	}
+
+	// RDS is being provisioned, requeue in 10 seconds
+	// we expect endpoint to be there because the status should be available
+	if rdsHostname == "" {
+		return stepWaiting("RdsEndpointPending", "Waiting for RDS to be available", 10*time.Second), nil
+	}
+
+	existingRdsStatusEndpoint := jira.Status.RDS.Endpoint
+	if existingRdsStatusEndpoint != rdsHostname {
+		jira.Status.RDS.Endpoint = rdsHostname
+		r.Recorder.Event(jira, corev1.EventTypeNormal, "RdsEndpointChanged", "RDS endpoint is "+rdsHostname)
+		logger.Info("Updating RDS endpoint in Jira status: " + rdsHostname)
+		err = r.Status().Update(context.TODO(), jira)
+		if err != nil {
+			return stepResult{}, err
+		}
+	}
+	return stepReady("RdsAvailable", "RDS is available at "+rdsHostname), nil
+}
+
+// credentialsStep points database secret to RDS endpoint, and resets root password of RDS restored from a snapshot
+type credentialsStep struct {
+	r *JiraReconciler
+}
+
+func (s *credentialsStep) Name() string {
+	return "Credentials"
+}
+
+func (s *credentialsStep) Ensure(ctx context.Context, jira *appv1.Jira) (stepResult, error) {
+	r := s.r
+	logger := log.FromContext(ctx)
+	namespace := k8s.GetNamespaceName(*jira)
+	rdsHostname := jira.Status.RDS.Endpoint
+
+	// at this point we should have RDS endpoint, let's update database secret with it
+	rdsSecret := k8s.GetRdsSecret(*jira, rdsHostname, namespace)
+	existingRdsSecretData, err := r.getSecretData(rdsSecret)
+	if err != nil {
+		return stepResult{}, err
+	}
+	rdsHostnameInSecret := string(existingRdsSecretData["hostname"])
+	if rdsHostnameInSecret != rdsHostname {
+		logger.Info("Updating RDS hostname in " + rdsSecret.Name + ": " + rdsHostname)
+		r.Recorder.Event(jira, corev1.EventTypeNormal, "SecretUpdated", "Updated RDS hostname in "+rdsSecret.Name)
+		err = r.Client.Update(context.TODO(), &rdsSecret)
+		if err != nil {
+			return stepResult{}, err
+		}
+	}
+
+	// when RDS is created from a snapshot root password is not automatically reset
+	// with a root password defined in the secret, so we need to run a k8s job to do it with aws cli
+	if jira.Spec.Database.SnapshotID != "" {
+		serviceAccount := k8s.GetServiceAccount(*jira, namespace)
+		err = r.apply(ctx, jira, &serviceAccount)
+		if err != nil {
+			return stepResult{}, err
+		}
+
+		changeRootPasswordJob := k8s.GetChangeRootPasswordJob(*jira, namespace, naming.ClusterScoped(*jira))
+		err = r.create(ctx, jira, &changeRootPasswordJob)
+		if err != nil {
+			return stepResult{}, err
+		}
+	}
+	return stepDone(), nil
+}
+
+func (s *credentialsStep) Ready(ctx context.Context, jira *appv1.Jira) (stepResult, error) {
+	r := s.r
+	if jira.Spec.Database.SnapshotID == "" {
+		return stepReady("SecretUpdated", "Database secret points to "+jira.Status.RDS.Endpoint), nil
+	}
+
+	changeRootPasswordJob := k8s.GetChangeRootPasswordJob(*jira, k8s.GetNamespaceName(*jira), naming.ClusterScoped(*jira))
+	jobSucceededReplicas, err := r.getJobSucceededReplicas(changeRootPasswordJob)
+	if err != nil {
+		return stepResult{}, err
+	}
+	jobFailed, err := r.getJobFailed(changeRootPasswordJob)
+	if err != nil {
+		return stepResult{}, err
+	}
+	if jobFailed {
+		return stepResult{}, r.retryFailedJob(ctx, jira, changeRootPasswordJob, "Reset RDS creds")
+	}
+	if jobSucceededReplicas < 1 {
+		return stepWaiting("ResetRdsCredsJobRunning", "Reset RDS creds job has the following number of succeeded replicas: "+strconv.Itoa(int(jobSucceededReplicas)), 5*time.Second), nil
+	}
+
+	if jira.Status.RDS.ResetRdsCredsJobStatus != "Succeeded" {
+		jira.Status.RDS.ResetRdsCredsJobStatus = "Succeeded"
+		r.Recorder.Event(jira, corev1.EventTypeNormal, "JobSucceeded", "Reset RDS creds job "+changeRootPasswordJob.Name+" succeeded")
+		err = r.Status().Update(context.TODO(), jira)
+		if err != nil {
+			return stepResult{}, err
+		}
+	}
+	return stepReady("RootPasswordReset", "RDS root password is reset to the one in database secret"), nil
+}
@@ -0,0 +1,188 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+	"github.com/atlassian-labs/jira-operator/helm"
+	"github.com/atlassian-labs/jira-operator/k8s"
+	"github.com/atlassian-labs/jira-operator/naming"
+	"github.com/atlassian-labs/jira-operator/product"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"strings"
+	"time"
+)
+
+// Sync and health of a delivered release, reported in Argo CD terms by all delivery backends
+const (
+	syncStatusSynced    = "Synced"
+	syncStatusOutOfSync = "OutOfSync"
+
+	healthStatusHealthy     = "Healthy"
+	healthStatusProgressing = "Progressing"
+	healthStatusDegraded    = "Degraded"
+	healthStatusMissing     = "Missing"
+)
+
+// delivery is a backend deploying Jira Helm chart
+type delivery interface {
+	// name of the delivery step
+	name() string
+	// deploy creates or updates the resources the backend deploys Jira from
+	deploy(ctx context.Context, jira *appv1.Jira) error
+	// status returns sync, health and last operation of the deployed release. Version and replicas are read from Jira StatefulSet instead
+	status(ctx context.Context, jira *appv1.Jira) (appStatus appv1.AppStatus, err error)
+}
+
+// deliveryStep returns the step deploying Jira with the delivery backend requested in Jira spec
+func (r *JiraReconciler) deliveryStep(jira *appv1.Jira) Step {
+	switch jira.Spec.Delivery.Type {
+	case appv1.DeliveryTypeFlux:
+		return &deliveryStep{r, &fluxDelivery{r}}
+	case appv1.DeliveryTypeHelm:
+		return &deliveryStep{r, &helmDelivery{r}}
+	default:
+		return &deliveryStep{r, &argoCDDelivery{r}}
+	}
+}
+
+// deliveryStep deploys Jira and tracks sync and health of the release in Jira status
+type deliveryStep struct {
+	r        *JiraReconciler
+	delivery delivery
+}
+
+func (s *deliveryStep) Name() string {
+	return s.delivery.name()
+}
+
+func (s *deliveryStep) Ensure(ctx context.Context, jira *appv1.Jira) (stepResult, error) {
+	// overrides are parsed ahead of rendering, as retrying does not help until they are fixed in spec
+	_, err := helm.GetValues(*jira)
+	if err != nil {
+		return stepResult{}, newSpecError("invalid spec.argocd.helmValues.valueOverrides: %w", err)
+	}
+	result, err := s.r.ensurePromotion(ctx, jira)
+	if err != nil || !result.Ready {
+		return result, err
+	}
+	err = s.delivery.deploy(ctx, getReleasedJira(jira))
+	if err != nil {
+		return stepResult{}, err
+	}
+	return stepDone(), nil
+}
+
+func (s *deliveryStep) Ready(ctx context.Context, jira *appv1.Jira) (stepResult, error) {
+	r := s.r
+	logger := log.FromContext(ctx)
+
+	appStatus, err := s.delivery.status(ctx, getReleasedJira(jira))
+	if err != nil {
+		return stepResult{}, err
+	}
+	syncStatus := appStatus.Sync
+	healthStatus := appStatus.Health
+
+	if jira.Status.AppStatus.Sync != syncStatus {
+		logger.Info("Updating app sync status to: " + syncStatus)
+		r.Recorder.Event(jira, corev1.EventTypeNormal, "ApplicationSyncChanged", "Application sync status changed to "+syncStatus)
+	}
+	if jira.Status.AppStatus.Health != healthStatus {
+		logger.Info("Updating app health status to: " + healthStatus)
+		healthEventType := corev1.EventTypeNormal
+		if healthStatus == healthStatusDegraded || healthStatus == healthStatusMissing {
+			healthEventType = corev1.EventTypeWarning
+		}
+		r.Recorder.Event(jira, healthEventType, "ApplicationHealthChanged", "Application health status changed to "+healthStatus)
+	}
+	// errors such as a missing values file only show in application conditions, so each new one gets an event
+	for _, condition := range appStatus.Conditions {
+		if !containsAppCondition(jira.Status.AppStatus.Conditions, condition) {
+			r.Recorder.Event(jira, corev1.EventTypeWarning, condition.Type, condition.Message)
+		}
+	}
+
+	// version and replicas are kept, they are updated from Jira StatefulSet below
+	appStatus.Version = jira.Status.AppStatus.Version
+	appStatus.Replicas = jira.Status.AppStatus.Replicas
+	appStatus.ReadyReplicas = jira.Status.AppStatus.ReadyReplicas
+	if !equality.Semantic.DeepEqual(jira.Status.AppStatus, appStatus) {
+		jira.Status.AppStatus = appStatus
+		err = r.Status().Update(context.TODO(), jira)
+		if err != nil {
+			return stepResult{}, err
+		}
+	}
+
+	err = r.updateJiraAppStatus(ctx, jira)
+	if err != nil {
+		return stepResult{}, err
+	}
+
+	// a rollout in progress is rolled back when the application is not healthy in time, rather than waited for
+	rolloutResult, err := r.checkRollout(ctx, jira, appStatus)
+	if err != nil || rolloutResult.Reason != "" {
+		return rolloutResult, err
+	}
+
+	// requeue if app is not yet healthy
+	if healthStatus != healthStatusHealthy {
+		message := "Application is not yet healthy. Current status: " + healthStatus
+		if len(appStatus.Conditions) > 0 {
+			message += ". " + appStatus.Conditions[0].Type + ": " + appStatus.Conditions[0].Message
+		}
+		return stepWaiting("ApplicationNotHealthy", message, 5*time.Minute), nil
+	}
+	return stepReady("ApplicationHealthy", "Application is "+syncStatus+" and Healthy"), nil
+}
+
+func containsAppCondition(conditions []appv1.AppCondition, condition appv1.AppCondition) bool {
+	for _, existing := range conditions {
+		if existing == condition {
+			return true
+		}
+	}
+	return false
+}
+
+// updateJiraAppStatus reports the version and replicas Jira StatefulSet runs with.
+// The StatefulSet is created by the delivery backend on the destination cluster, so it does not exist until the release is synced
+func (r *JiraReconciler) updateJiraAppStatus(ctx context.Context, jira *appv1.Jira) (err error) {
+	logger := log.FromContext(ctx)
+	workloadClient, err := r.workloadClient(ctx, jira)
+	if err != nil {
+		return err
+	}
+	var jiraStatefulSet appsv1.StatefulSet
+	err = workloadClient.Get(context.TODO(), client.ObjectKey{Name: naming.JiraStatefulSet(*jira), Namespace: k8s.GetNamespaceName(*jira)}, &jiraStatefulSet)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	version := ""
+	for _, container := range jiraStatefulSet.Spec.Template.Spec.Containers {
+		if container.Name == product.Get(*jira).Chart {
+			version = container.Image[strings.LastIndex(container.Image, ":")+1:]
+		}
+	}
+	appStatus := jira.Status.AppStatus
+	if appStatus.Version == version && appStatus.Replicas == jiraStatefulSet.Status.Replicas && appStatus.ReadyReplicas == jiraStatefulSet.Status.ReadyReplicas {
+		return nil
+	}
+	if appStatus.Version != version {
+		r.Recorder.Event(jira, corev1.EventTypeNormal, "VersionChanged", "Jira runs version "+version)
+	}
+	logger.Info(fmt.Sprintf("Updating Jira version to %s with %d/%d ready replicas", version, jiraStatefulSet.Status.ReadyReplicas, jiraStatefulSet.Status.Replicas))
+	jira.Status.AppStatus.Version = version
+	jira.Status.AppStatus.Replicas = jiraStatefulSet.Status.Replicas
+	jira.Status.AppStatus.ReadyReplicas = jiraStatefulSet.Status.ReadyReplicas
+	return r.Status().Update(context.TODO(), jira)
+}
@@ -0,0 +1,191 @@
+package controllers
+
+import (
+	"context"
+	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+	"github.com/atlassian-labs/jira-operator/crossplane"
+	"github.com/atlassian-labs/jira-operator/k8s"
+	"github.com/atlassian-labs/jira-operator/naming"
+	route53 "github.com/crossplane-contrib/provider-aws/apis/route53/v1alpha1"
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"net"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sort"
+	"time"
+)
+
+// dnsStep points Jira hostname to the load balancer of its ingress with a Route53 alias record managed by Crossplane,
+// instead of leaving it to external-dns
+type dnsStep struct {
+	r *JiraReconciler
+}
+
+func (s *dnsStep) Name() string {
+	return "DNS"
+}
+
+func (s *dnsStep) Ensure(ctx context.Context, jira *appv1.Jira) (stepResult, error) {
+	r := s.r
+	logger := log.FromContext(ctx)
+	if jira.Spec.DNS.HostedZoneId == "" {
+		return stepResult{}, newSpecError("spec.dns.hostedZoneId is required with %s DNS provider", appv1.DNSProviderRoute53)
+	}
+
+	// blue/green stacks which do not serve the hostname have no record
+	if jira.Annotations[appv1.ExternalDNSDisabledAnnotation] == "true" {
+		return stepDone(), r.deleteResourceRecordSet(ctx, jira)
+	}
+
+	// the record of a previous hostname is deleted first, as the record of the new one is the same managed resource
+	deleted, err := r.deleteStaleResourceRecordSet(ctx, jira)
+	if err != nil {
+		return stepResult{}, err
+	}
+	if !deleted {
+		return stepWaiting("DeletingRecord", "Waiting for the record of the previous hostname of "+jira.Name+" to be deleted", 30*time.Second), nil
+	}
+
+	workloadClient, err := r.workloadClient(ctx, jira)
+	if err != nil {
+		return stepResult{}, err
+	}
+	var ingress networkingv1.Ingress
+	err = workloadClient.Get(context.TODO(), client.ObjectKey{Name: naming.JiraIngress(*jira), Namespace: k8s.GetNamespaceName(*jira)}, &ingress)
+	if errors.IsNotFound(err) {
+		return stepWaiting("WaitingForIngress", "Waiting for ingress "+naming.JiraIngress(*jira)+" to be deployed", 30*time.Second), nil
+	} else if err != nil {
+		return stepResult{}, err
+	}
+	loadBalancer := ""
+	if len(ingress.Status.LoadBalancer.Ingress) > 0 {
+		loadBalancer = ingress.Status.LoadBalancer.Ingress[0].Hostname
+	}
+	if loadBalancer == "" {
+		return stepWaiting("WaitingForLoadBalancer", "Waiting for load balancer of ingress "+ingress.Name, 30*time.Second), nil
+	}
+
+	loadBalancerHostedZoneId, err := crossplane.GetLoadBalancerHostedZoneId(*jira)
+	if err != nil {
+		return stepResult{}, newSpecError("%w", err)
+	}
+	recordSet := crossplane.GetResourceRecordSet(*jira, loadBalancer, loadBalancerHostedZoneId)
+	err = r.apply(ctx, jira, &recordSet)
+	if err != nil {
+		return stepResult{}, err
+	}
+
+	if jira.Status.DNS.LoadBalancer != loadBalancer {
+		logger.Info("Pointing " + jira.Spec.Hostname + " to load balancer " + loadBalancer)
+		r.Recorder.Event(jira, corev1.EventTypeNormal, "RecordUpdated", jira.Spec.Hostname+" is an alias of "+loadBalancer)
+		jira.Status.DNS.LoadBalancer = loadBalancer
+		err = r.Status().Update(context.TODO(), jira)
+		if err != nil {
+			return stepResult{}, err
+		}
+	}
+	return stepDone(), nil
+}
+
+func (s *dnsStep) Ready(ctx context.Context, jira *appv1.Jira) (stepResult, error) {
+	r := s.r
+	if jira.Annotations[appv1.ExternalDNSDisabledAnnotation] == "true" {
+		return stepReady("RecordDisabled", jira.Spec.Hostname+" is not served by "+jira.Name), nil
+	}
+
+	var recordSet route53.ResourceRecordSet
+	err := r.Get(context.TODO(), client.ObjectKey{Name: naming.ClusterScoped(*jira)}, &recordSet)
+	if err != nil {
+		return stepResult{}, err
+	}
+	if recordSet.GetCondition(xpv1.TypeSynced).Status != corev1.ConditionTrue || recordSet.GetCondition(xpv1.TypeReady).Status != corev1.ConditionTrue {
+		return stepWaiting("WaitingForRecord", "Waiting for Route53 record "+jira.Spec.Hostname+" to be ready", 30*time.Second), nil
+	}
+
+	// the record may not have propagated yet, addresses are reported once it resolves
+	addresses, _ := net.LookupHost(jira.Spec.Hostname)
+	sort.Strings(addresses)
+	if !equality.Semantic.DeepEqual(jira.Status.DNS.Addresses, addresses) {
+		jira.Status.DNS.Addresses = addresses
+		err = r.Status().Update(context.TODO(), jira)
+		if err != nil {
+			return stepResult{}, err
+		}
+	}
+	return stepReady("RecordReady", jira.Spec.Hostname+" is an alias of "+jira.Status.DNS.LoadBalancer), nil
+}
+
+// deleteStaleResourceRecordSet deletes the record of Jira when it is not the one of its hostname, which changed since
+// the record was created, and returns whether there is no such record left. Crossplane would otherwise keep the record
+// of the previous hostname in Route53, and only update the external name of the managed resource
+func (r *JiraReconciler) deleteStaleResourceRecordSet(ctx context.Context, jira *appv1.Jira) (deleted bool, err error) {
+	logger := log.FromContext(ctx)
+	var recordSet route53.ResourceRecordSet
+	err = r.Get(context.TODO(), client.ObjectKey{Name: naming.ClusterScoped(*jira)}, &recordSet)
+	if errors.IsNotFound(err) {
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+	previousHostname := meta.GetExternalName(&recordSet)
+	if previousHostname == "" || previousHostname == jira.Spec.Hostname {
+		return true, nil
+	}
+	if recordSet.GetDeletionTimestamp() != nil {
+		return false, nil
+	}
+
+	// the record is deleted from Route53 even when Jira retains its resources on delete, as nothing serves the previous hostname
+	if recordSet.GetDeletionPolicy() != xpv1.DeletionDelete {
+		err = r.Patch(context.TODO(), &recordSet, client.RawPatch(types.MergePatchType, []byte(`{"spec":{"deletionPolicy":"Delete"}}`)))
+		if err != nil {
+			return false, err
+		}
+	}
+	logger.Info("Deleting Route53 record of previous hostname " + previousHostname)
+	r.Recorder.Event(jira, corev1.EventTypeNormal, "DeletingRecord", "Deleting record of previous hostname "+previousHostname)
+	err = r.Delete(context.TODO(), &recordSet)
+	if err != nil && !errors.IsNotFound(err) {
+		return false, err
+	}
+	if jira.Status.DNS.LoadBalancer != "" {
+		jira.Status.DNS = appv1.DNSStatus{}
+		err = r.Status().Update(context.TODO(), jira)
+	}
+	return false, err
+}
+
+// deleteResourceRecordSet deletes the record of Jira hostname. The record is orphaned, as the same name may already
+// point to the load balancer of another blue/green stack
+func (r *JiraReconciler) deleteResourceRecordSet(ctx context.Context, jira *appv1.Jira) (err error) {
+	logger := log.FromContext(ctx)
+	var recordSet route53.ResourceRecordSet
+	err = r.Get(context.TODO(), client.ObjectKey{Name: naming.ClusterScoped(*jira)}, &recordSet)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if recordSet.GetDeletionPolicy() != xpv1.DeletionOrphan {
+		err = r.Patch(context.TODO(), &recordSet, client.RawPatch(types.MergePatchType, []byte(`{"spec":{"deletionPolicy":"Orphan"}}`)))
+		if err != nil {
+			return err
+		}
+	}
+	logger.Info("Deleting Route53 record of " + jira.Spec.Hostname + ": " + recordSet.Name)
+	err = r.Delete(context.TODO(), &recordSet)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err == nil && jira.Status.DNS.LoadBalancer != "" {
+		jira.Status.DNS = appv1.DNSStatus{}
+		err = r.Status().Update(context.TODO(), jira)
+	}
+	return err
+}
@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/atlassian-labs/jira-operator/naming"
+	route53 "github.com/crossplane-contrib/provider-aws/apis/route53/v1alpha1"
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestDeleteStaleResourceRecordSet(t *testing.T) {
+	recordSet := func(hostname string, deleting bool) []client.Object {
+		recordSet := &route53.ResourceRecordSet{ObjectMeta: metav1.ObjectMeta{Name: naming.ClusterScoped(*newTestJira())}}
+		recordSet.SetDeletionPolicy(xpv1.DeletionOrphan)
+		meta.SetExternalName(recordSet, hostname)
+		if deleting {
+			now := metav1.Now()
+			recordSet.SetDeletionTimestamp(&now)
+			recordSet.SetFinalizers([]string{"finalizer.managedresource.crossplane.io"})
+		}
+		return []client.Object{recordSet}
+	}
+	tests := []struct {
+		name        string
+		objects     []client.Object
+		wantDeleted bool
+		wantRecord  bool
+	}{
+		{"no record", nil, true, false},
+		{"record of the hostname", recordSet("jira.example.com", false), true, true},
+		{"record of the previous hostname", recordSet("old.example.com", false), false, false},
+		{"record of the previous hostname being deleted", recordSet("old.example.com", true), false, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			jira := newTestJira()
+			jira.Spec.Hostname = "jira.example.com"
+			jira.Status.DNS.LoadBalancer = "old-alb.elb.amazonaws.com"
+			r, jira := newTestReconciler(t, jira, test.objects...)
+			deleted, err := r.deleteStaleResourceRecordSet(context.TODO(), jira)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if deleted != test.wantDeleted {
+				t.Errorf("deleteStaleResourceRecordSet() = %t, want %t", deleted, test.wantDeleted)
+			}
+
+			var stored route53.ResourceRecordSet
+			err = r.Get(context.TODO(), client.ObjectKey{Name: naming.ClusterScoped(*jira)}, &stored)
+			if errors.IsNotFound(err) == test.wantRecord {
+				t.Errorf("record exists = %t, want %t", err == nil, test.wantRecord)
+			}
+			if !test.wantDeleted && !test.wantRecord && jira.Status.DNS.LoadBalancer != "" {
+				t.Errorf("load balancer of the previous hostname %s is still recorded", jira.Status.DNS.LoadBalancer)
+			}
+		})
+	}
+}
+
+func TestIsKindInstalled(t *testing.T) {
+	mapper := apimeta.NewDefaultRESTMapper(nil)
+	installed, err := isKindInstalled(mapper, route53.ResourceRecordSetGroupVersionKind)
+	if err != nil || installed {
+		t.Errorf("isKindInstalled() = %t, %v, want false without the CRD", installed, err)
+	}
+	mapper.Add(route53.ResourceRecordSetGroupVersionKind, apimeta.RESTScopeRoot)
+	installed, err = isKindInstalled(mapper, route53.ResourceRecordSetGroupVersionKind)
+	if err != nil || !installed {
+		t.Errorf("isKindInstalled() = %t, %v, want true with the CRD", installed, err)
+	}
+}
@@ -0,0 +1,178 @@
+package controllers
+
+import (
+	"context"
+	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+	"github.com/atlassian-labs/jira-operator/crossplane"
+	"github.com/atlassian-labs/jira-operator/k8s"
+	"github.com/atlassian-labs/jira-operator/naming"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"strconv"
+	"time"
+)
+
+// ebsSharedHomeStep exports an EBS volume as shared home through an nfs server
+type ebsSharedHomeStep struct {
+	r *JiraReconciler
+}
+
+func (s *ebsSharedHomeStep) Name() string {
+	return sharedHomeStepName
+}
+
+func (s *ebsSharedHomeStep) Ensure(ctx context.Context, jira *appv1.Jira) (stepResult, error) {
+	r := s.r
+	logger := log.FromContext(ctx)
+	namespace := k8s.GetNamespaceName(*jira)
+
+	// create EBS volume, from a snapshot if there is one. Volumes are applied at the size they were provisioned or
+	// expanded to, a raised size is applied by the resize below
+	ebsVolume := crossplane.GetEbsVolume(withVolumeSize(jira, getEbsVolumeSize(jira)))
+	volumeSize := strconv.Itoa(int(getProvisionedSize(jira)))
+	err := r.apply(ctx, jira, &ebsVolume)
+	if err != nil {
+		return stepResult{}, err
+	}
+
+	ebsVolumeId, err := r.getEbsVolumeId(ebsVolume, client.ObjectKey{Name: naming.ClusterScoped(*jira)})
+	if err != nil {
+		return stepResult{}, err
+	}
+
+	if ebsVolumeId == "" {
+		return stepWaiting("EbsVolumePending", "Ebs volume ID is not yet available", 5*time.Second), nil
+	}
+
+	// create nfs-server PersistentVolume using EBS volume handle
+	nfsPersistentVolume := k8s.GetEbsPersistentVolume(*jira, ebsVolumeId, naming.NfsServer(*jira), volumeSize, namespace)
+	err = r.apply(ctx, jira, &nfsPersistentVolume)
+	if err != nil {
+		return stepResult{}, err
+	}
+
+	// create nfs-server PersistentVolumeClaim
+	nfsPersistentVolumeClaim := k8s.GetPersistentVolumeClaim(*jira, naming.NfsServer(*jira), namespace, nfsPersistentVolume.Name, jira.Spec.SharedFS.Ebs.EbsStorageClassName, volumeSize, corev1.ReadWriteOnce)
+	err = r.apply(ctx, jira, &nfsPersistentVolumeClaim)
+	if err != nil {
+		return stepResult{}, err
+	}
+
+	// an empty volume has to be owned by Jira user before nfs server exports it
+	if jira.Spec.SharedFS.Ebs.SnapshotId == "" {
+		nfsInitJob := k8s.GetNfsInitJob(*jira, namespace)
+		err = r.create(ctx, jira, &nfsInitJob)
+		if err != nil {
+			return stepResult{}, err
+		}
+
+		nfsInitJobSucceededReplicas, err := r.getJobSucceededReplicas(nfsInitJob)
+		if err != nil {
+			return stepResult{}, err
+		}
+		nfsInitJobFailed, err := r.getJobFailed(nfsInitJob)
+		if err != nil {
+			return stepResult{}, err
+		}
+		if nfsInitJobFailed {
+			return stepResult{}, r.retryFailedJob(ctx, jira, nfsInitJob, "NFS init")
+		}
+		if nfsInitJobSucceededReplicas < 1 {
+			return stepWaiting("NfsInitJobRunning", "NFS init job has the following number of succeeded replicas: "+strconv.Itoa(int(nfsInitJobSucceededReplicas)), 10*time.Second), nil
+		}
+
+		if jira.Status.SharedFilesystemStatus.EbsInitJobStatus != "Succeeded" {
+			jira.Status.SharedFilesystemStatus.EbsInitJobStatus = "Succeeded"
+			r.Recorder.Event(jira, corev1.EventTypeNormal, "JobSucceeded", "NFS init job "+nfsInitJob.Name+" succeeded")
+			err = r.Status().Update(context.TODO(), jira)
+			if err != nil {
+				return stepResult{}, err
+			}
+		}
+	}
+
+	// create nfs-server svc
+	nfsServerService := k8s.GetNfSServerService(*jira, namespace)
+	err = r.apply(ctx, jira, &nfsServerService)
+	if err != nil {
+		return stepResult{}, err
+	}
+
+	// get nfs server svc cluster IP
+	nfsServerIp, err := r.getSvcClusterIp(nfsServerService)
+	if err != nil {
+		return stepResult{}, err
+	}
+
+	if nfsServerIp == "" {
+		return stepWaiting("NfsServerServicePending", "No ClusterIP available for nfs server service", 30*time.Second), nil
+	}
+
+	// create nfs-server StatefulSet
+	nfsServerStatefulSet := k8s.GetNfsServerStatefulSet(*jira, namespace)
+	err = r.apply(ctx, jira, &nfsServerStatefulSet)
+	if err != nil {
+		return stepResult{}, err
+	}
+
+	// protect nfs server from voluntary evictions, PDBs created while it was on by default are removed when it is off
+	nfsServerPdb := k8s.GetNfsServerPodDisruptionBudget(*jira, namespace)
+	if jira.Spec.SharedFS.NfsServer.PodDisruptionBudget {
+		err = r.apply(ctx, jira, &nfsServerPdb)
+	} else {
+		err = client.IgnoreNotFound(r.Delete(context.TODO(), &nfsServerPdb))
+	}
+	if err != nil {
+		return stepResult{}, err
+	}
+
+	// get nfs-server statefulset status, shared home is not ready while nfs server is not
+	nfsReadyReplicas, err := r.getStsReadyReplicas(nfsServerStatefulSet)
+	if err != nil {
+		return stepResult{}, err
+	}
+	if nfsReadyReplicas < 1 {
+		return stepWaiting("NfsServerNotReady", "NFS server "+nfsServerStatefulSet.Name+" has no ready replicas", 10*time.Second), nil
+	}
+
+	// create nfs jira shared-home PV
+	jiraSharedHomeNfsPv := k8s.GetNfsPersistentVolume(*jira, nfsServerIp, volumeSize, namespace)
+	err = r.apply(ctx, jira, &jiraSharedHomeNfsPv)
+	if err != nil {
+		return stepResult{}, err
+	}
+
+	// create jira shared home pvc bound to nfs shared home pv
+	sharedHomePvcAccessMode := corev1.ReadWriteMany
+	jiraSharedHomePvcNfs := k8s.GetPersistentVolumeClaim(*jira, naming.SharedHomeClaim(*jira), namespace, jiraSharedHomeNfsPv.Name, jira.Spec.SharedFS.Efs.EfsStorageClassName, volumeSize, sharedHomePvcAccessMode)
+	err = r.apply(ctx, jira, &jiraSharedHomePvcNfs)
+	if err != nil {
+		return stepResult{}, err
+	}
+
+	// grow shared home when SharedFS.VolumeSize is raised
+	resized, err := r.resizeEbsSharedHome(ctx, jira, ebsVolumeId, []string{nfsPersistentVolume.Name, jiraSharedHomeNfsPv.Name}, []corev1.PersistentVolumeClaim{nfsPersistentVolumeClaim, jiraSharedHomePvcNfs}, nfsServerStatefulSet.Name+"-0", namespace)
+	if err != nil {
+		return stepResult{}, err
+	}
+	if !resized {
+		return stepWaiting("Resizing", "Waiting for shared home to be expanded", 30*time.Second), nil
+	}
+
+	currentEbsId := jira.Status.SharedFilesystemStatus.EbsId
+	if currentEbsId != ebsVolumeId {
+		logger.Info("Updating Jira status with EBS ID: " + ebsVolumeId)
+		jira.Status.SharedFilesystemStatus.EbsId = ebsVolumeId
+		r.Recorder.Event(jira, corev1.EventTypeNormal, "SharedHomeBound", "Shared home is bound to EBS volume "+ebsVolumeId)
+		err = r.Status().Update(context.TODO(), jira)
+		if err != nil {
+			return stepResult{}, err
+		}
+	}
+	return stepDone(), nil
+}
+
+func (s *ebsSharedHomeStep) Ready(ctx context.Context, jira *appv1.Jira) (stepResult, error) {
+	return stepReady("NfsServerReady", "Shared home is exported by NFS server "+naming.NfsServer(*jira)), nil
+}
@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"context"
+	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+	"github.com/atlassian-labs/jira-operator/crossplane"
+	"github.com/atlassian-labs/jira-operator/k8s"
+	"github.com/atlassian-labs/jira-operator/metrics"
+	"github.com/atlassian-labs/jira-operator/naming"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"time"
+)
+
+// efsSharedHomeStep mounts shared home from a new or an existing EFS
+type efsSharedHomeStep struct {
+	r *JiraReconciler
+}
+
+func (s *efsSharedHomeStep) Name() string {
+	return sharedHomeStepName
+}
+
+func (s *efsSharedHomeStep) Ensure(ctx context.Context, jira *appv1.Jira) (stepResult, error) {
+	r := s.r
+	logger := log.FromContext(ctx)
+	namespace := k8s.GetNamespaceName(*jira)
+
+	fileSystemId := jira.Spec.SharedFS.Efs.FileSystemId
+	if fileSystemId == "" {
+		// create a new EFS
+		sharedFileSystem := crossplane.GetFileSystem(*jira, namespace)
+		err := r.apply(ctx, jira, &sharedFileSystem)
+		if err != nil {
+			return stepResult{}, err
+		}
+
+		newFileSystemId, lifeCycleState, err := r.getFilesystemStatus(sharedFileSystem, client.ObjectKey{Name: naming.ClusterScoped(*jira)})
+		if err != nil {
+			return stepResult{}, err
+		}
+		metrics.SetEfsStatus(jira.Name, sharedFileSystem.Name, lifeCycleState)
+
+		if newFileSystemId == nil || *newFileSystemId == "" {
+			return stepWaiting("EfsPending", "No filesystem ID is available yet", 5*time.Second), nil
+		}
+		fileSystemId = *newFileSystemId
+
+		// create mountTargets
+		for i, subnetId := range jira.Spec.Network.SubnetIDs {
+			mountTarget := crossplane.GetMountTargets(*jira, fileSystemId, subnetId, i)
+			err = r.apply(ctx, jira, &mountTarget)
+			if err != nil {
+				return stepResult{}, err
+			}
+			mountTargetStatus, err := r.getMountTargetStatus(mountTarget, client.ObjectKey{Name: mountTarget.Name})
+			if err != nil {
+				return stepResult{}, err
+			}
+			if mountTargetStatus == nil || *mountTargetStatus != "available" {
+				return stepWaiting("MountTargetNotAvailable", "Mount target is not available: "+mountTarget.Name, 10*time.Second), nil
+			}
+		}
+	}
+
+	// a shared filesystem is always mounted through a per-instance access point,
+	// so that Jira instances sharing it do not see each other's shared home
+	accessPointId := ""
+	if jira.Spec.SharedFS.Efs.FileSystemId != "" || jira.Spec.SharedFS.Efs.AccessPoint.Enabled {
+		accessPoint := crossplane.GetAccessPoint(*jira, fileSystemId)
+		err := r.apply(ctx, jira, &accessPoint)
+		if err != nil {
+			return stepResult{}, err
+		}
+
+		var accessPointStatus string
+		accessPointId, accessPointStatus, err = r.getAccessPointStatus(accessPoint, client.ObjectKey{Name: accessPoint.Name})
+		if err != nil {
+			return stepResult{}, err
+		}
+		if accessPointId == "" || accessPointStatus != "available" {
+			return stepWaiting("AccessPointNotAvailable", "Access point is not available: "+accessPoint.Name, 10*time.Second), nil
+		}
+	}
+
+	// create Persistent Volume using efs id
+	efsPersistentVolume := k8s.GetEfsPersistentVolume(*jira, fileSystemId, accessPointId, namespace)
+	err := r.apply(ctx, jira, &efsPersistentVolume)
+	if err != nil {
+		return stepResult{}, err
+	}
+
+	sharedHomePvcAccessMode := corev1.ReadWriteMany
+	efsPersistentVolumeClaim := k8s.GetPersistentVolumeClaim(*jira, naming.SharedHomeClaim(*jira), namespace, efsPersistentVolume.Name, jira.Spec.SharedFS.Efs.EfsStorageClassName, "10", sharedHomePvcAccessMode)
+	err = r.apply(ctx, jira, &efsPersistentVolumeClaim)
+	if err != nil {
+		return stepResult{}, err
+	}
+
+	// grow shared home when SharedFS.VolumeSize is raised
+	err = r.resizeSharedHomeClaim(ctx, jira, efsPersistentVolumeClaim)
+	if err != nil {
+		return stepResult{}, err
+	}
+
+	currentEfsId := jira.Status.SharedFilesystemStatus.EfsId
+	currentAccessPointId := jira.Status.SharedFilesystemStatus.EfsAccessPointId
+	if currentEfsId != fileSystemId || currentAccessPointId != accessPointId {
+		logger.Info("Updating Jira status with EFS ID: " + k8s.GetEfsVolumeHandle(fileSystemId, accessPointId))
+		jira.Status.SharedFilesystemStatus.EfsId = fileSystemId
+		jira.Status.SharedFilesystemStatus.EfsAccessPointId = accessPointId
+		r.Recorder.Event(jira, corev1.EventTypeNormal, "SharedHomeBound", "Shared home is bound to EFS "+k8s.GetEfsVolumeHandle(fileSystemId, accessPointId))
+		err = r.Status().Update(context.TODO(), jira)
+		if err != nil {
+			return stepResult{}, err
+		}
+	}
+	return stepDone(), nil
+}
+
+func (s *efsSharedHomeStep) Ready(ctx context.Context, jira *appv1.Jira) (stepResult, error) {
+	sharedFilesystemStatus := jira.Status.SharedFilesystemStatus
+	return stepReady("EfsVolumeCreated", "Shared home is mounted from EFS "+k8s.GetEfsVolumeHandle(sharedFilesystemStatus.EfsId, sharedFilesystemStatus.EfsAccessPointId)), nil
+}
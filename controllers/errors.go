@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"time"
+)
+
+// errorKind classifies reconciliation errors, it decides how a failed step is retried
+type errorKind string
+
+const (
+	// errorKindTransient is retried with controller-runtime rate limiter exponential backoff
+	errorKindTransient errorKind = "TransientError"
+	// errorKindWaiting is not a failure, the step is retried with a backoff growing with the time it has been waiting
+	errorKindWaiting errorKind = "WaitingForDependency"
+	// errorKindSpec can only be fixed by changing Jira spec, reconciliation stalls until its generation changes
+	errorKindSpec errorKind = "InvalidSpec"
+	// errorKindPermanent needs manual intervention, reconciliation stalls until Jira generation changes
+	errorKindPermanent errorKind = "PermanentError"
+)
+
+const (
+	minWaitingBackoff = 5 * time.Second
+	maxWaitingBackoff = 5 * time.Minute
+)
+
+type reconcileError struct {
+	kind errorKind
+	err  error
+}
+
+func (e *reconcileError) Error() string {
+	return e.err.Error()
+}
+
+func (e *reconcileError) Unwrap() error {
+	return e.err
+}
+
+func newWaitingError(format string, args ...interface{}) error {
+	return &reconcileError{kind: errorKindWaiting, err: fmt.Errorf(format, args...)}
+}
+
+func newSpecError(format string, args ...interface{}) error {
+	return &reconcileError{kind: errorKindSpec, err: fmt.Errorf(format, args...)}
+}
+
+func newPermanentError(format string, args ...interface{}) error {
+	return &reconcileError{kind: errorKindPermanent, err: fmt.Errorf(format, args...)}
+}
+
+// classifyError returns the kind of an error. Errors not created with one of the constructors above are classified
+// by their API status: missing resources are waited for, anything else is transient. Resources the API server rejects
+// as invalid are mostly rendered wrong by the operator rather than from invalid spec, which is validated by the CRD
+// and the steps themselves, so they are retried with backoff instead of stalling until spec changes
+func classifyError(err error) errorKind {
+	var reconcileErr *reconcileError
+	switch {
+	case errors.As(err, &reconcileErr):
+		return reconcileErr.kind
+	case apierrors.IsNotFound(err):
+		return errorKindWaiting
+	default:
+		return errorKindTransient
+	}
+}
+
+// stalls reports whether reconciliation should stop retrying until Jira spec changes
+func (k errorKind) stalls() bool {
+	return k == errorKindSpec || k == errorKindPermanent
+}
+
+// waitingBackoff returns how long to wait before checking a step again. It grows with the time the step
+// has been waiting, so that slow resources such as RDS are not polled at the same rate as a job about to complete
+func waitingBackoff(startedAt time.Time, hint time.Duration) time.Duration {
+	minBackoff := hint
+	if minBackoff < minWaitingBackoff {
+		minBackoff = minWaitingBackoff
+	}
+	backoff := time.Since(startedAt) / 2
+	if backoff < minBackoff {
+		backoff = minBackoff
+	}
+	if backoff > maxWaitingBackoff {
+		backoff = maxWaitingBackoff
+	}
+	return backoff
+}
@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+	"github.com/atlassian-labs/jira-operator/k8s"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestClassifyError(t *testing.T) {
+	jiraResource := schema.GroupResource{Group: "app.atlassian.com", Resource: "jiras"}
+	tests := []struct {
+		name string
+		err  error
+		want errorKind
+	}{
+		{"waiting error", newWaitingError("cluster %s is not registered", "remote"), errorKindWaiting},
+		{"spec error", newSpecError("spec.dns.hostedZoneId is required"), errorKindSpec},
+		{"permanent error", newPermanentError("snapshot failed"), errorKindPermanent},
+		{"wrapped spec error", fmt.Errorf("rendering values: %w", newSpecError("invalid values")), errorKindSpec},
+		{"not found", apierrors.NewNotFound(jiraResource, "jira"), errorKindWaiting},
+		{"invalid", apierrors.NewInvalid(schema.GroupKind{Kind: "Job"}, "job", field.ErrorList{field.Required(field.NewPath("spec"), "")}), errorKindTransient},
+		{"conflict", apierrors.NewConflict(jiraResource, "jira", errors.New("modified")), errorKindTransient},
+		{"plain error", errors.New("connection refused"), errorKindTransient},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := classifyError(test.err); got != test.want {
+				t.Errorf("classifyError() = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestErrorKindStalls(t *testing.T) {
+	tests := []struct {
+		kind errorKind
+		want bool
+	}{
+		{errorKindTransient, false},
+		{errorKindWaiting, false},
+		{errorKindSpec, true},
+		{errorKindPermanent, true},
+	}
+	for _, test := range tests {
+		t.Run(string(test.kind), func(t *testing.T) {
+			if got := test.kind.stalls(); got != test.want {
+				t.Errorf("stalls() = %t, want %t", got, test.want)
+			}
+		})
+	}
+}
+
+func TestWaitingBackoff(t *testing.T) {
+	tests := []struct {
+		name    string
+		waiting time.Duration
+		hint    time.Duration
+		min     time.Duration
+		max     time.Duration
+	}{
+		{"just started", 0, 0, minWaitingBackoff, minWaitingBackoff},
+		{"hint above minimum", 0, 30 * time.Second, 30 * time.Second, 30 * time.Second},
+		{"grows with waiting time", 2 * time.Minute, 0, time.Minute, time.Minute + time.Second},
+		{"capped", time.Hour, 0, maxWaitingBackoff, maxWaitingBackoff},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := waitingBackoff(time.Now().Add(-test.waiting), test.hint)
+			if got < test.min || got > test.max {
+				t.Errorf("waitingBackoff() = %s, want between %s and %s", got, test.min, test.max)
+			}
+		})
+	}
+}
+
+func TestFailedJobIsRetried(t *testing.T) {
+	jira := newTestJira()
+	liquibaseJob := k8s.GetLiquibaseJob(*jira, k8s.GetNamespaceName(*jira))
+	liquibaseJob.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue}}
+	r, jira := newTestReconciler(t, jira, &liquibaseJob)
+
+	_, err := (&migrationsStep{r}).Ready(context.TODO(), jira)
+	if err == nil {
+		t.Fatal("Ready() succeeded with a failed job")
+	}
+	if kind := classifyError(err); kind.stalls() {
+		t.Errorf("failed job error is %s, which stalls until spec changes", kind)
+	}
+	getErr := r.Get(context.TODO(), client.ObjectKeyFromObject(&liquibaseJob), &batchv1.Job{})
+	if !apierrors.IsNotFound(getErr) {
+		t.Errorf("failed job was not deleted to be created again: %v", getErr)
+	}
+
+	_, err = r.handleStepError(context.TODO(), jira, "Migrations", err)
+	if err == nil {
+		t.Error("handleStepError() did not return the error to be retried with backoff")
+	}
+	if meta.IsStatusConditionTrue(jira.Status.Conditions, appv1.ConditionStalled) {
+		t.Error("a failed job stalled reconciliation")
+	}
+}
@@ -0,0 +1,48 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+	route53 "github.com/crossplane-contrib/provider-aws/apis/route53/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// newTestReconciler returns a reconciler backed by a fake client holding objects, and the Jira read back from it
+func newTestReconciler(t *testing.T, jira *appv1.Jira, objects ...client.Object) (*JiraReconciler, *appv1.Jira) {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := appv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := route53.SchemeBuilder.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&appv1.Jira{}).
+		WithObjects(append(objects, jira)...).
+		Build()
+	r := &JiraReconciler{Client: fakeClient, Scheme: scheme, Recorder: record.NewFakeRecorder(100)}
+	stored := &appv1.Jira{}
+	if err := fakeClient.Get(context.TODO(), client.ObjectKeyFromObject(jira), stored); err != nil {
+		t.Fatal(err)
+	}
+	return r, stored
+}
+
+func newTestJira() *appv1.Jira {
+	return &appv1.Jira{
+		ObjectMeta: metav1.ObjectMeta{Name: "jira", UID: types.UID("c0ffee"), Generation: 1},
+	}
+}
@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"context"
+	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+	"github.com/atlassian-labs/jira-operator/flux"
+	"github.com/atlassian-labs/jira-operator/k8s"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// fluxDelivery deploys Jira with a Flux HelmRelease in Jira namespace
+type fluxDelivery struct {
+	r *JiraReconciler
+}
+
+func (d *fluxDelivery) name() string {
+	return "Flux"
+}
+
+func (d *fluxDelivery) deploy(ctx context.Context, jira *appv1.Jira) (err error) {
+	if !jira.Spec.ArgoCD.Destination.IsLocal() {
+		return newSpecError("spec.argocd.destination is not supported with flux delivery")
+	}
+	helmRepository := flux.GetHelmRepository(*jira)
+	err = d.r.apply(ctx, jira, helmRepository)
+	if err != nil {
+		return err
+	}
+	helmRelease, err := flux.GetHelmRelease(*jira)
+	if err != nil {
+		return err
+	}
+	return d.r.apply(ctx, jira, helmRelease)
+}
+
+// status maps HelmRelease to Argo CD statuses: it is synced once Flux observed its latest generation,
+// and healthy, degraded or progressing when its Ready condition is true, false or unknown
+func (d *fluxDelivery) status(ctx context.Context, jira *appv1.Jira) (appStatus appv1.AppStatus, err error) {
+	helmRelease := &unstructured.Unstructured{}
+	helmRelease.SetGroupVersionKind(flux.HelmReleaseGVK)
+	err = d.r.Get(ctx, client.ObjectKey{Name: jira.Name, Namespace: k8s.GetNamespaceName(*jira)}, helmRelease)
+	if errors.IsNotFound(err) {
+		return appv1.AppStatus{Sync: syncStatusOutOfSync, Health: healthStatusMissing}, nil
+	} else if err != nil {
+		return appStatus, err
+	}
+
+	appStatus.Sync = syncStatusOutOfSync
+	observedGeneration, _, _ := unstructured.NestedInt64(helmRelease.Object, "status", "observedGeneration")
+	if observedGeneration == helmRelease.GetGeneration() {
+		appStatus.Sync = syncStatusSynced
+	}
+	revision, _, _ := unstructured.NestedString(helmRelease.Object, "status", "lastAppliedRevision")
+	if revision != "" {
+		appStatus.Revisions = []string{revision}
+	}
+
+	appStatus.Health = healthStatusProgressing
+	conditions, _, _ := unstructured.NestedSlice(helmRelease.Object, "status", "conditions")
+	for _, condition := range conditions {
+		conditionMap, ok := condition.(map[string]interface{})
+		if !ok || conditionMap["type"] != "Ready" {
+			continue
+		}
+		switch conditionMap["status"] {
+		case "True":
+			appStatus.Health = healthStatusHealthy
+		case "False":
+			appStatus.Health = healthStatusDegraded
+			message, _ := conditionMap["message"].(string)
+			reason, _ := conditionMap["reason"].(string)
+			appStatus.Conditions = append(appStatus.Conditions, appv1.AppCondition{Type: reason, Message: message})
+		}
+		appStatus.OperationMessage, _ = conditionMap["message"].(string)
+	}
+	return appStatus, nil
+}
@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/atlassian-labs/jira-operator/flux"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestFluxStatus(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         map[string]interface{}
+		wantSync       string
+		wantHealth     string
+		wantConditions int
+	}{
+		{"not reconciled by Flux yet", nil, syncStatusOutOfSync, healthStatusProgressing, 0},
+		{"ready", map[string]interface{}{
+			"observedGeneration":  int64(2),
+			"lastAppliedRevision": "1.10.0",
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Released", "status": "False", "reason": "InstallFailed"},
+				map[string]interface{}{"type": "Ready", "status": "True", "reason": "ReconciliationSucceeded", "message": "Release reconciliation succeeded"},
+			},
+		}, syncStatusSynced, healthStatusHealthy, 0},
+		{"not ready", map[string]interface{}{
+			"observedGeneration": int64(2),
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Ready", "status": "False", "reason": "UpgradeFailed", "message": "timed out waiting for the condition"},
+			},
+		}, syncStatusSynced, healthStatusDegraded, 1},
+		{"reconciling an older generation", map[string]interface{}{
+			"observedGeneration": int64(1),
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Ready", "status": "Unknown", "reason": "Progressing"},
+			},
+		}, syncStatusOutOfSync, healthStatusProgressing, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			helmRelease := &unstructured.Unstructured{Object: map[string]interface{}{}}
+			helmRelease.SetGroupVersionKind(flux.HelmReleaseGVK)
+			helmRelease.SetName("jira")
+			helmRelease.SetNamespace("jira")
+			helmRelease.SetGeneration(2)
+			if test.status != nil {
+				helmRelease.Object["status"] = test.status
+			}
+			r, jira := newTestReconciler(t, newTestJira(), helmRelease)
+
+			appStatus, err := (&fluxDelivery{r}).status(context.TODO(), jira)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if appStatus.Sync != test.wantSync || appStatus.Health != test.wantHealth || len(appStatus.Conditions) != test.wantConditions {
+				t.Errorf("status = %+v, want sync %s, health %s and %d conditions", appStatus, test.wantSync, test.wantHealth, test.wantConditions)
+			}
+		})
+	}
+}
+
+func TestFluxStatusMissing(t *testing.T) {
+	r, jira := newTestReconciler(t, newTestJira())
+	appStatus, err := (&fluxDelivery{r}).status(context.TODO(), jira)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if appStatus.Health != healthStatusMissing {
+		t.Errorf("health = %s, want %s", appStatus.Health, healthStatusMissing)
+	}
+}
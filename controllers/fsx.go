@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"context"
+	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+	"github.com/atlassian-labs/jira-operator/k8s"
+	"github.com/atlassian-labs/jira-operator/naming"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"strconv"
+	"time"
+)
+
+// fsxSharedHomeStep provisions shared home on an FSx volume, restored from a snapshot if there is one
+type fsxSharedHomeStep struct {
+	r *JiraReconciler
+}
+
+func (s *fsxSharedHomeStep) Name() string {
+	return sharedHomeStepName
+}
+
+func (s *fsxSharedHomeStep) Ensure(ctx context.Context, jira *appv1.Jira) (stepResult, error) {
+	r := s.r
+	namespace := k8s.GetNamespaceName(*jira)
+	volumeSize := strconv.Itoa(int(getProvisionedSize(jira)))
+
+	if jira.Spec.SharedFS.Fsx.SnapshotId == "" {
+		// provision a new volume through FSx CSI storage class
+		fsxPvc := k8s.GetFsxPersistentVolumeClaim(*jira, naming.SharedHomeClaim(*jira), namespace, volumeSize)
+		err := r.apply(ctx, jira, &fsxPvc)
+		if err != nil {
+			return stepResult{}, err
+		}
+		return stepDone(), nil
+	}
+
+	// create VolumeSnapshot from existing vol handle
+	volumeSnapshotContent := k8s.GetFsxVolumeSnapshotContent(*jira, namespace)
+	err := r.apply(ctx, jira, &volumeSnapshotContent)
+	if err != nil {
+		return stepResult{}, err
+	}
+
+	volumeSnapshot := k8s.GetFsxVolumeSnapshot(*jira, namespace)
+	err = r.apply(ctx, jira, &volumeSnapshot)
+	if err != nil {
+		return stepResult{}, err
+	}
+
+	readyToUse, err := r.getVolumeSnapshotReadyToUse(volumeSnapshot)
+	if err != nil {
+		return stepResult{}, err
+	}
+	if !readyToUse {
+		err = r.setFsxVolumeStatus(ctx, jira, "WaitingForSnapshot")
+		return stepWaiting("WaitingForSnapshot", "Waiting for FSx VolumeSnapshot to be ready to use: "+volumeSnapshot.Name, 30*time.Second), err
+	}
+
+	fsxPvc := k8s.GetFsxPersistentVolumeClaimFromSnapshot(*jira, naming.SharedHomeClaim(*jira), namespace, volumeSize)
+	err = r.apply(ctx, jira, &fsxPvc)
+	if err != nil {
+		return stepResult{}, err
+	}
+	return stepDone(), nil
+}
+
+func (s *fsxSharedHomeStep) Ready(ctx context.Context, jira *appv1.Jira) (stepResult, error) {
+	r := s.r
+	logger := log.FromContext(ctx)
+	namespace := k8s.GetNamespaceName(*jira)
+	volumeSize := strconv.Itoa(int(getProvisionedSize(jira)))
+
+	fsxPvc := k8s.GetFsxPersistentVolumeClaim(*jira, naming.SharedHomeClaim(*jira), namespace, volumeSize)
+	fsxPvcPendingStatus := "Provisioning"
+	if jira.Spec.SharedFS.Fsx.SnapshotId != "" {
+		fsxPvc = k8s.GetFsxPersistentVolumeClaimFromSnapshot(*jira, naming.SharedHomeClaim(*jira), namespace, volumeSize)
+		fsxPvcPendingStatus = "Restoring"
+	}
+
+	fsxPvcStatus, err := r.getPvcStatus(fsxPvc)
+	if err != nil {
+		return stepResult{}, err
+	}
+
+	if fsxPvcStatus != "Bound" {
+		err = r.setFsxVolumeStatus(ctx, jira, fsxPvcPendingStatus)
+		return stepWaiting(fsxPvcPendingStatus, "Waiting for FSX PVC "+fsxPvc.Name+" to be in Bound state. Current status: "+fsxPvcStatus, 30*time.Second), err
+	}
+
+	fsxVolumeName, err := r.getFsxVolumeName(fsxPvc)
+	if err != nil {
+		return stepResult{}, err
+	}
+
+	// grow shared home when SharedFS.VolumeSize is raised
+	err = r.resizeSharedHomeClaim(ctx, jira, fsxPvc)
+	if err != nil {
+		return stepResult{}, err
+	}
+
+	currentFsxId := jira.Status.SharedFilesystemStatus.FsxId
+	if currentFsxId != fsxVolumeName || jira.Status.SharedFilesystemStatus.FsxVolumeStatus != "Bound" {
+		logger.Info("Updating Jira status with FSX volume: " + fsxVolumeName)
+		jira.Status.SharedFilesystemStatus.FsxId = fsxVolumeName
+		jira.Status.SharedFilesystemStatus.FsxVolumeStatus = "Bound"
+		r.Recorder.Event(jira, corev1.EventTypeNormal, "SharedHomeBound", "Shared home is bound to FSx volume "+fsxVolumeName)
+		err = r.Status().Update(context.TODO(), jira)
+		if err != nil {
+			return stepResult{}, err
+		}
+	}
+	return stepReady("FsxVolumeBound", "Shared home is bound to FSx volume "+fsxVolumeName), nil
+}
+
+// setFsxVolumeStatus reports restore or creation progress, since FSx volumes may take a while to be ready
+func (r *JiraReconciler) setFsxVolumeStatus(ctx context.Context, jira *appv1.Jira, fsxVolumeStatus string) (err error) {
+	logger := log.FromContext(ctx)
+	if jira.Status.SharedFilesystemStatus.FsxVolumeStatus == fsxVolumeStatus {
+		return nil
+	}
+	logger.Info("Updating FSx volume status to: " + fsxVolumeStatus)
+	jira.Status.SharedFilesystemStatus.FsxVolumeStatus = fsxVolumeStatus
+	return r.Status().Update(context.TODO(), jira)
+}
@@ -0,0 +1,135 @@
+package controllers
+
+import (
+	"context"
+	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+	"github.com/atlassian-labs/jira-operator/helm"
+	"github.com/atlassian-labs/jira-operator/k8s"
+	"github.com/atlassian-labs/jira-operator/naming"
+	"helm.sh/helm/v3/pkg/release"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// helmDelivery installs Jira chart from the operator with Helm SDK, and upgrades the release when Jira spec changes
+type helmDelivery struct {
+	r *JiraReconciler
+}
+
+func (d *helmDelivery) name() string {
+	return "Helm"
+}
+
+func (d *helmDelivery) deploy(ctx context.Context, jira *appv1.Jira) (err error) {
+	if !jira.Spec.ArgoCD.Destination.IsLocal() {
+		return newSpecError("spec.argocd.destination is not supported with helm delivery")
+	}
+	// the release is installed only once Jira can no longer be deleted without uninstalling it.
+	// Only the finalizer is patched, as the chart version deployed may differ from spec during a rollout
+	if !controllerutil.ContainsFinalizer(jira, appv1.HelmReleaseFinalizer) {
+		patch := client.MergeFrom(jira.DeepCopy())
+		controllerutil.AddFinalizer(jira, appv1.HelmReleaseFinalizer)
+		err = d.r.Patch(ctx, jira, patch)
+		if err != nil {
+			return err
+		}
+	}
+
+	existing, err := helm.GetRelease(d.r.RestConfig, *jira)
+	if err != nil {
+		return err
+	}
+	rel, err := helm.InstallOrUpgrade(ctx, d.r.RestConfig, *jira)
+	if err != nil {
+		return err
+	}
+	if existing == nil || existing.Version != rel.Version {
+		d.r.Recorder.Eventf(jira, corev1.EventTypeNormal, "HelmReleaseDeployed", "Deployed revision %d of release %s with chart %s",
+			rel.Version, rel.Name, rel.Chart.Metadata.Version)
+	}
+	return nil
+}
+
+// status maps the Helm release to Argo CD statuses: it is synced when it runs chart version and values of Jira spec,
+// and progressing or degraded when it is being installed or upgraded, or failed. Install and upgrade do not wait for
+// resources of the chart, so a deployed release is only healthy once Jira StatefulSet is rolled out and ready
+func (d *helmDelivery) status(ctx context.Context, jira *appv1.Jira) (appStatus appv1.AppStatus, err error) {
+	rel, err := helm.GetRelease(d.r.RestConfig, *jira)
+	if err != nil {
+		return appStatus, err
+	}
+	if rel == nil {
+		return appv1.AppStatus{Sync: syncStatusOutOfSync, Health: healthStatusMissing}, nil
+	}
+
+	appStatus.Sync = syncStatusOutOfSync
+	upToDate, err := helm.IsUpToDate(rel, *jira)
+	if err != nil {
+		return appStatus, err
+	}
+	if upToDate {
+		appStatus.Sync = syncStatusSynced
+	}
+
+	switch {
+	case rel.Info.Status == release.StatusDeployed:
+		appStatus.Health, err = d.r.getStatefulSetHealth(ctx, jira)
+		if err != nil {
+			return appStatus, err
+		}
+	case rel.Info.Status.IsPending():
+		appStatus.Health = healthStatusProgressing
+	case rel.Info.Status == release.StatusFailed:
+		appStatus.Health = healthStatusDegraded
+	default:
+		appStatus.Health = healthStatusMissing
+	}
+	appStatus.Revisions = []string{rel.Chart.Metadata.Version}
+	appStatus.OperationPhase = string(rel.Info.Status)
+	appStatus.OperationMessage = rel.Info.Description
+	if !rel.Info.LastDeployed.IsZero() {
+		appStatus.LastSyncedAt = &metav1.Time{Time: rel.Info.LastDeployed.Time}
+	}
+	return appStatus, nil
+}
+
+// getStatefulSetHealth returns healthy when all replicas of Jira StatefulSet run its latest revision and are ready, and progressing otherwise
+func (r *JiraReconciler) getStatefulSetHealth(ctx context.Context, jira *appv1.Jira) (string, error) {
+	var statefulSet appsv1.StatefulSet
+	err := r.Get(ctx, client.ObjectKey{Name: naming.JiraStatefulSet(*jira), Namespace: k8s.GetNamespaceName(*jira)}, &statefulSet)
+	if errors.IsNotFound(err) {
+		return healthStatusProgressing, nil
+	} else if err != nil {
+		return "", err
+	}
+	replicas := int32(1)
+	if statefulSet.Spec.Replicas != nil {
+		replicas = *statefulSet.Spec.Replicas
+	}
+	status := statefulSet.Status
+	if status.ObservedGeneration < statefulSet.Generation || status.UpdatedReplicas < replicas || status.ReadyReplicas < replicas ||
+		status.Replicas > replicas {
+		return healthStatusProgressing, nil
+	}
+	return healthStatusHealthy, nil
+}
+
+// finalize uninstalls the Helm release of a deleted Jira before letting it go
+func (r *JiraReconciler) finalize(ctx context.Context, jira *appv1.Jira) (err error) {
+	logger := log.FromContext(ctx)
+	if !controllerutil.ContainsFinalizer(jira, appv1.HelmReleaseFinalizer) {
+		return nil
+	}
+	logger.Info("Uninstalling Helm release " + jira.Name)
+	err = helm.Uninstall(ctx, r.RestConfig, *jira)
+	if err != nil {
+		return err
+	}
+	controllerutil.RemoveFinalizer(jira, appv1.HelmReleaseFinalizer)
+	return r.Update(ctx, jira)
+}
@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/atlassian-labs/jira-operator/naming"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestGetStatefulSetHealth(t *testing.T) {
+	statefulSet := func(replicas int32, status appsv1.StatefulSetStatus) []client.Object {
+		return []client.Object{&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: naming.JiraStatefulSet(*newTestJira()), Namespace: "jira", Generation: 2},
+			Spec:       appsv1.StatefulSetSpec{Replicas: pointer.Int32(replicas)},
+			Status:     status,
+		}}
+	}
+	tests := []struct {
+		name    string
+		objects []client.Object
+		want    string
+	}{
+		{"deployed but StatefulSet not created yet", nil, healthStatusProgressing},
+		{"deployed but not ready", statefulSet(2, appsv1.StatefulSetStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, ReadyReplicas: 1}),
+			healthStatusProgressing},
+		{"upgrade not observed yet", statefulSet(2, appsv1.StatefulSetStatus{ObservedGeneration: 1, Replicas: 2, UpdatedReplicas: 2, ReadyReplicas: 2}),
+			healthStatusProgressing},
+		{"rolling update in progress", statefulSet(2, appsv1.StatefulSetStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 1, ReadyReplicas: 2}),
+			healthStatusProgressing},
+		{"ready", statefulSet(2, appsv1.StatefulSetStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, ReadyReplicas: 2}),
+			healthStatusHealthy},
+		{"scaled down", statefulSet(0, appsv1.StatefulSetStatus{ObservedGeneration: 2}), healthStatusHealthy},
+		{"scaling down", statefulSet(0, appsv1.StatefulSetStatus{ObservedGeneration: 2, Replicas: 1}), healthStatusProgressing},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r, jira := newTestReconciler(t, newTestJira(), test.objects...)
+			got, err := r.getStatefulSetHealth(context.TODO(), jira)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("getStatefulSetHealth() = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"context"
+	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+	"github.com/atlassian-labs/jira-operator/k8s"
+	"github.com/atlassian-labs/jira-operator/naming"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strconv"
+	"time"
+)
+
+// migrationsStep runs liquibase changesets against the database
+type migrationsStep struct {
+	r *JiraReconciler
+}
+
+func (s *migrationsStep) Name() string {
+	return "Migrations"
+}
+
+func (s *migrationsStep) Ensure(ctx context.Context, jira *appv1.Jira) (stepResult, error) {
+	r := s.r
+	namespace := k8s.GetNamespaceName(*jira)
+
+	// get liquibase changelog configmap definition and create it
+	liquibaseConfigMap, err := k8s.GetLiquibaseConfigMap(*jira, namespace)
+	if err != nil {
+		return stepResult{}, newPermanentError("failed to read liquibase changelog file: %w", err)
+	}
+	err = r.apply(ctx, jira, &liquibaseConfigMap)
+	if err != nil {
+		return stepResult{}, err
+	}
+
+	err = r.backfillDatabaseParameters(ctx, jira, namespace)
+	if err != nil {
+		return stepResult{}, err
+	}
+
+	// create liquibase job
+	liquibaseJob := k8s.GetLiquibaseJob(*jira, namespace)
+	err = r.create(ctx, jira, &liquibaseJob)
+	if err != nil {
+		return stepResult{}, err
+	}
+	return stepDone(), nil
+}
+
+func (s *migrationsStep) Ready(ctx context.Context, jira *appv1.Jira) (stepResult, error) {
+	r := s.r
+	liquibaseJob := k8s.GetLiquibaseJob(*jira, k8s.GetNamespaceName(*jira))
+	liquibaseJobSucceededReplicas, err := r.getJobSucceededReplicas(liquibaseJob)
+	if err != nil {
+		return stepResult{}, err
+	}
+	liquibaseJobFailed, err := r.getJobFailed(liquibaseJob)
+	if err != nil {
+		return stepResult{}, err
+	}
+	if liquibaseJobFailed {
+		return stepResult{}, r.retryFailedJob(ctx, jira, liquibaseJob, "Liquibase")
+	}
+
+	if liquibaseJobSucceededReplicas < 1 {
+		return stepWaiting("LiquibaseJobRunning", "Liquibase changeset job has the following number of succeeded replicas: "+strconv.Itoa(int(liquibaseJobSucceededReplicas)), 5*time.Second), nil
+	}
+
+	if jira.Status.RDS.LiquibaseJobStatus != "Succeeded" {
+		jira.Status.RDS.LiquibaseJobStatus = "Succeeded"
+		r.Recorder.Event(jira, corev1.EventTypeNormal, "JobSucceeded", "Liquibase job "+liquibaseJob.Name+" succeeded")
+		err = r.Status().Update(context.TODO(), jira)
+		if err != nil {
+			return stepResult{}, err
+		}
+	}
+	return stepReady("LiquibaseJobSucceeded", "Liquibase changesets are applied"), nil
+}
+
+// backfillDatabaseParameters adds product database parameters to a database secret created before products were supported,
+// as liquibase changelog reads them from the secret
+func (r *JiraReconciler) backfillDatabaseParameters(ctx context.Context, jira *appv1.Jira, namespace string) error {
+	var databaseSecret corev1.Secret
+	err := r.Get(ctx, client.ObjectKey{Name: naming.DatabaseSecret(*jira), Namespace: namespace}, &databaseSecret)
+	if err != nil {
+		return err
+	}
+	missing := false
+	for key, value := range k8s.GetProductDatabaseParameters(*jira) {
+		if _, ok := databaseSecret.Data[key]; !ok {
+			if databaseSecret.Data == nil {
+				databaseSecret.Data = map[string][]byte{}
+			}
+			databaseSecret.Data[key] = value
+			missing = true
+		}
+	}
+	if !missing {
+		return nil
+	}
+	r.Recorder.Event(jira, corev1.EventTypeNormal, "SecretUpdated", "Added product database parameters to "+databaseSecret.Name)
+	return r.Update(ctx, &databaseSecret)
+}
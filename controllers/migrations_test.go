@@ -0,0 +1,43 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+	"github.com/atlassian-labs/jira-operator/naming"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestBackfillDatabaseParameters(t *testing.T) {
+	jira := newTestJira()
+	jira.Spec.Product = appv1.ProductConfluence
+	// database secret created before products were supported
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: naming.DatabaseSecret(*jira), Namespace: "jira"},
+		Data:       map[string][]byte{"password": []byte("secret"), "parameter.appUsername": []byte("jira")},
+	}
+	r, jira := newTestReconciler(t, jira, secret)
+	err := r.backfillDatabaseParameters(context.TODO(), jira, "jira")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stored := &corev1.Secret{}
+	if err := r.Get(context.TODO(), client.ObjectKeyFromObject(secret), stored); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"password":                "secret",
+		"parameter.appDatabase":   "confluence",
+		"parameter.appUsername":   "jira",
+		"parameter.appRoUsername": "confluence-ro",
+	}
+	for key, value := range want {
+		if string(stored.Data[key]) != value {
+			t.Errorf("%s = %q, want %q", key, stored.Data[key], value)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package controllers
+
+import (
+	"context"
+	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+	"github.com/atlassian-labs/jira-operator/k8s"
+	"github.com/atlassian-labs/jira-operator/naming"
+	"github.com/atlassian-labs/jira-operator/product"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"strconv"
+)
+
+// namespaceStep creates or adopts the namespace Jira and its dependencies are deployed to
+type namespaceStep struct {
+	r *JiraReconciler
+}
+
+func (s *namespaceStep) Name() string {
+	return "Namespace"
+}
+
+func (s *namespaceStep) Ensure(ctx context.Context, jira *appv1.Jira) (stepResult, error) {
+	// checked in the first step, as names and values of all resources depend on the product
+	err := product.Validate(*jira)
+	if err != nil {
+		return stepResult{}, newSpecError("%w", err)
+	}
+	err = s.r.detectLegacyNames(ctx, jira)
+	if err != nil {
+		return stepResult{}, err
+	}
+	err = s.r.reconcileNamespace(ctx, jira)
+	if err != nil {
+		return stepResult{}, err
+	}
+	return stepDone(), nil
+}
+
+func (s *namespaceStep) Ready(ctx context.Context, jira *appv1.Jira) (stepResult, error) {
+	return stepReady("NamespaceReconciled", "Namespace "+jira.Status.Namespace+" is reconciled"), nil
+}
+
+// reconcileNamespace creates or adopts the target namespace according to TargetNamespace mode,
+// keeps its labels and annotations in line with the spec, and creates ResourceQuota and LimitRange in it
+func (r *JiraReconciler) reconcileNamespace(ctx context.Context, jira *appv1.Jira) (err error) {
+	desiredNamespace := k8s.GetNamespace(*jira)
+	var namespace corev1.Namespace
+	err = r.Get(context.TODO(), client.ObjectKey{Name: desiredNamespace.Name}, &namespace)
+	found := err == nil
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	mode := jira.Spec.TargetNamespace.Mode
+	if mode == "" || mode == appv1.NamespaceModeCreate {
+		// a namespace of the same name created for something else, e.g. another team or another Jira, is never taken over
+		if found && !metav1.IsControlledBy(&namespace, jira) {
+			return newSpecError("namespace %s already exists and is not owned by %s, set spec.targetNamespace.name or adopt it", desiredNamespace.Name, jira.Name)
+		}
+	} else if !found {
+		// adopted namespaces must already exist, only labels and annotations are applied to them
+		return newWaitingError("namespace %s to adopt does not exist", desiredNamespace.Name)
+	}
+
+	// labels and annotations set outside of the operator are kept
+	err = r.apply(ctx, jira, &desiredNamespace)
+	if err != nil {
+		return err
+	}
+
+	if jira.Spec.TargetNamespace.ResourceQuota != nil {
+		resourceQuota := k8s.GetResourceQuota(*jira, desiredNamespace.Name)
+		err = r.apply(ctx, jira, &resourceQuota)
+		if err != nil {
+			return err
+		}
+	}
+	if jira.Spec.TargetNamespace.LimitRange != nil {
+		limitRange := k8s.GetLimitRange(*jira, desiredNamespace.Name)
+		err = r.apply(ctx, jira, &limitRange)
+		if err != nil {
+			return err
+		}
+	}
+
+	if jira.Status.Namespace != desiredNamespace.Name {
+		jira.Status.Namespace = desiredNamespace.Name
+		err = r.Status().Update(context.TODO(), jira)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// detectLegacyNames sets LegacyNamesAnnotation on Jiras whose children were created with fixed names, and to "false" on
+// others so that they are checked only once. Legacy Jiras are recognised by children whose fixed name is never the name
+// derived from a Jira: the database secret owned by the Jira unless the Jira is named jira, the service account of the
+// RDS password reset job, the shared home volume named after the Jira UID, or the governing service of nfs server
+func (r *JiraReconciler) detectLegacyNames(ctx context.Context, jira *appv1.Jira) (err error) {
+	logger := log.FromContext(ctx)
+	if _, ok := jira.Annotations[appv1.LegacyNamesAnnotation]; ok {
+		return nil
+	}
+	namespace := k8s.GetNamespaceName(*jira)
+	legacy := false
+	if naming.DatabaseSecret(*jira) != naming.LegacyDatabaseSecret {
+		var secret corev1.Secret
+		legacy, err = r.exists(ctx, client.ObjectKey{Name: naming.LegacyDatabaseSecret, Namespace: namespace}, &secret)
+		if err != nil {
+			return err
+		}
+		legacy = legacy && metav1.IsControlledBy(&secret, jira)
+	}
+	for _, marker := range []struct {
+		key    client.ObjectKey
+		object client.Object
+	}{
+		{client.ObjectKey{Name: naming.LegacyResetRdsPasswordServiceAccount, Namespace: namespace}, &corev1.ServiceAccount{}},
+		{client.ObjectKey{Name: naming.LegacySharedHomePersistentVolume(*jira)}, &corev1.PersistentVolume{}},
+		{client.ObjectKey{Name: naming.LegacyNfsServerService, Namespace: namespace}, &corev1.Service{}},
+	} {
+		if legacy {
+			break
+		}
+		legacy, err = r.exists(ctx, marker.key, marker.object)
+		if err != nil {
+			return err
+		}
+	}
+
+	if legacy {
+		logger.Info("Keeping legacy names of children of " + jira.Name)
+	}
+	patch := client.MergeFrom(jira.DeepCopy())
+	if jira.Annotations == nil {
+		jira.Annotations = map[string]string{}
+	}
+	jira.Annotations[appv1.LegacyNamesAnnotation] = strconv.FormatBool(legacy)
+	return r.Patch(ctx, jira, patch)
+}
+
+// exists gets an object and returns whether it exists
+func (r *JiraReconciler) exists(ctx context.Context, key client.ObjectKey, object client.Object) (bool, error) {
+	err := r.Get(ctx, key, object)
+	if errors.IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+	"github.com/atlassian-labs/jira-operator/k8s"
+	"github.com/atlassian-labs/jira-operator/naming"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestReconcileNamespaceRefusesNamespaces(t *testing.T) {
+	foreign := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "jira"}}
+	tests := []struct {
+		name     string
+		mode     string
+		objects  []client.Object
+		wantKind errorKind
+	}{
+		{"created namespace owned by someone else", "", []client.Object{foreign}, errorKindSpec},
+		{"create mode namespace owned by someone else", appv1.NamespaceModeCreate, []client.Object{foreign}, errorKindSpec},
+		{"adopted namespace does not exist", appv1.NamespaceModeAdopt, nil, errorKindWaiting},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			jira := newTestJira()
+			jira.Spec.TargetNamespace = appv1.TargetNamespaceSpec{Mode: test.mode, Name: "jira"}
+			r, jira := newTestReconciler(t, jira, test.objects...)
+			err := r.reconcileNamespace(context.TODO(), jira)
+			if err == nil {
+				t.Fatal("reconcileNamespace() succeeded, want error")
+			}
+			if kind := classifyError(err); kind != test.wantKind {
+				t.Errorf("error kind = %s, want %s: %v", kind, test.wantKind, err)
+			}
+		})
+	}
+}
+
+func TestNamespaceStepRefusesUnknownProduct(t *testing.T) {
+	jira := newTestJira()
+	jira.Spec.Product = "bamboo"
+	r, jira := newTestReconciler(t, jira)
+	_, err := (&namespaceStep{r}).Ensure(context.TODO(), jira)
+	if kind := classifyError(err); kind != errorKindSpec {
+		t.Errorf("error kind = %s, want %s: %v", kind, errorKindSpec, err)
+	}
+}
+
+func TestDetectLegacyNames(t *testing.T) {
+	owned := func(object client.Object, jira *appv1.Jira) client.Object {
+		object.SetOwnerReferences(k8s.GetOwnerReferences(*jira))
+		return object
+	}
+	tests := []struct {
+		name    string
+		jira    string
+		objects func(jira *appv1.Jira) []client.Object
+		want    string
+	}{
+		{"new Jira", "jira-dev", func(*appv1.Jira) []client.Object { return nil }, "false"},
+		{"new Jira named jira owning the secret it derives", "jira", func(jira *appv1.Jira) []client.Object {
+			return []client.Object{owned(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: naming.LegacyDatabaseSecret, Namespace: "jira"}}, jira)}
+		}, "false"},
+		{"legacy database secret owned by Jira", "jira-dev", func(jira *appv1.Jira) []client.Object {
+			return []client.Object{owned(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: naming.LegacyDatabaseSecret, Namespace: "jira-dev"}}, jira)}
+		}, "true"},
+		{"legacy database secret owned by someone else", "jira-dev", func(*appv1.Jira) []client.Object {
+			return []client.Object{&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: naming.LegacyDatabaseSecret, Namespace: "jira-dev"}}}
+		}, "false"},
+		{"legacy service account", "jira", func(*appv1.Jira) []client.Object {
+			return []client.Object{&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: naming.LegacyResetRdsPasswordServiceAccount, Namespace: "jira"}}}
+		}, "true"},
+		{"legacy shared home volume", "jira", func(jira *appv1.Jira) []client.Object {
+			return []client.Object{&corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: naming.LegacySharedHomePersistentVolume(*jira)}}}
+		}, "true"},
+		{"legacy nfs server service", "jira", func(*appv1.Jira) []client.Object {
+			return []client.Object{&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: naming.LegacyNfsServerService, Namespace: "jira"}}}
+		}, "true"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			jira := newTestJira()
+			jira.Name = test.jira
+			r, jira := newTestReconciler(t, jira, test.objects(jira)...)
+			err := r.detectLegacyNames(context.TODO(), jira)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			stored := &appv1.Jira{}
+			if err := r.Get(context.TODO(), client.ObjectKeyFromObject(jira), stored); err != nil {
+				t.Fatal(err)
+			}
+			if got := stored.Annotations[appv1.LegacyNamesAnnotation]; got != test.want {
+				t.Errorf("legacy names = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDetectLegacyNamesOnlyOnce(t *testing.T) {
+	jira := newTestJira()
+	r, jira := newTestReconciler(t, jira)
+	err := r.detectLegacyNames(context.TODO(), jira)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the database secret a new Jira named jira creates later on does not make it legacy
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: naming.DatabaseSecret(*jira), Namespace: "jira", OwnerReferences: k8s.GetOwnerReferences(*jira)}}
+	if err := r.Create(context.TODO(), secret); err != nil {
+		t.Fatal(err)
+	}
+	err = r.detectLegacyNames(context.TODO(), jira)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if jira.Annotations[appv1.LegacyNamesAnnotation] != "false" || naming.SharedHomeClaim(*jira) != "jira-shared-home" ||
+		naming.NfsServerService(*jira) != "jira-nfs-server" {
+		t.Errorf("Jira named jira uses legacy names: %v", jira.Annotations)
+	}
+}
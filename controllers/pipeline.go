@@ -0,0 +1,290 @@
+package controllers
+
+import (
+	"context"
+	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+	"github.com/atlassian-labs/jira-operator/metrics"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"time"
+)
+
+// Step is a stage of Jira reconciliation. Steps run in order, and a step runs only when all previous ones are ready
+type Step interface {
+	// Name of the step, its condition type is the name followed by Ready
+	Name() string
+	// Ensure creates or updates resources of the step. It returns a not ready result
+	// when it has to wait for a resource before the next one can be created
+	Ensure(ctx context.Context, jira *appv1.Jira) (stepResult, error)
+	// Ready reports whether resources of the step are ready to be used by the following steps
+	Ready(ctx context.Context, jira *appv1.Jira) (stepResult, error)
+}
+
+// stepResult is the outcome of a step. Reason and Message end up in the step condition
+type stepResult struct {
+	Ready   bool
+	Reason  string
+	Message string
+	// RequeueAfter is the shortest delay before a step which is not ready is checked again
+	RequeueAfter time.Duration
+}
+
+const (
+	stepReasonReady      = "Ready"
+	stepReasonInProgress = "InProgress"
+)
+
+// stepDone is returned by Ensure when all resources of the step are created
+func stepDone() stepResult {
+	return stepResult{Ready: true}
+}
+
+func stepReady(reason string, message string) stepResult {
+	return stepResult{Ready: true, Reason: reason, Message: message}
+}
+
+func stepWaiting(reason string, message string, requeueAfter time.Duration) stepResult {
+	return stepResult{Reason: reason, Message: message, RequeueAfter: requeueAfter}
+}
+
+// steps returns reconciliation stages of a Jira in the order they run
+func (r *JiraReconciler) steps(jira *appv1.Jira) []Step {
+	steps := []Step{
+		&namespaceStep{r},
+		&databaseStep{r},
+		&credentialsStep{r},
+		&migrationsStep{r},
+		r.sharedHomeStep(jira),
+		r.deliveryStep(jira),
+	}
+	if jira.Spec.DNS.Provider == appv1.DNSProviderRoute53 {
+		steps = append(steps, &dnsStep{r})
+	}
+	if jira.Spec.BlueGreen.Enabled {
+		steps = append(steps, &blueGreenStep{r})
+	}
+	return steps
+}
+
+// runPipeline runs steps in order until one of them fails or is not ready yet, recording a condition,
+// start and completion time and the last error of each step in Jira status.
+// How a failed step is retried depends on the kind of its error, see classifyError
+func (r *JiraReconciler) runPipeline(ctx context.Context, jira *appv1.Jira, steps []Step) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	// a paused Jira is left as is, including resources which drifted
+	if jira.IsPaused() {
+		metrics.SetPhase(jira.Name, metrics.PhasePaused)
+		logger.Info("Reconciliation is paused")
+		return ctrl.Result{}, r.setCondition(jira, appv1.ConditionPaused, metav1.ConditionTrue, "Paused", "Reconciliation is paused through spec.paused or "+appv1.PausedAnnotation+" annotation")
+	}
+	if meta.IsStatusConditionTrue(jira.Status.Conditions, appv1.ConditionPaused) {
+		err := r.setCondition(jira, appv1.ConditionPaused, metav1.ConditionFalse, "Resumed", "Reconciliation is resumed")
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	// in maintenance Argo CD scales Jira down, while all other steps keep running
+	if jira.Spec.Maintenance {
+		err := r.setCondition(jira, appv1.ConditionMaintenance, metav1.ConditionTrue, "MaintenanceEnabled", "Jira is scaled down to 0 replicas")
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	} else if meta.FindStatusCondition(jira.Status.Conditions, appv1.ConditionMaintenance) != nil {
+		err := r.setCondition(jira, appv1.ConditionMaintenance, metav1.ConditionFalse, "MaintenanceDisabled", "Jira is scaled back up")
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	// a stalled Jira is not reconciled again until its spec changes
+	stalled := meta.FindStatusCondition(jira.Status.Conditions, appv1.ConditionStalled)
+	if stalled != nil && stalled.Status == metav1.ConditionTrue {
+		if stalled.ObservedGeneration == jira.Generation {
+			metrics.SetPhase(jira.Name, metrics.PhaseStalled)
+			logger.Info("Reconciliation is stalled until spec changes: " + stalled.Message)
+			return ctrl.Result{}, nil
+		}
+		err := r.setCondition(jira, appv1.ConditionStalled, metav1.ConditionFalse, "SpecChanged", "Spec changed, retrying reconciliation")
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	for _, step := range steps {
+		start := time.Now()
+		result, err := step.Ensure(ctx, jira)
+		if err == nil && result.Ready {
+			result, err = step.Ready(ctx, jira)
+		}
+		logger.Info("Ran step "+step.Name(), "ready", result.Ready, "duration", time.Since(start).String())
+
+		recordErr := r.recordStep(jira, step.Name(), result, err)
+		if !result.Ready || err != nil {
+			metrics.SetPhase(jira.Name, step.Name())
+		}
+		if err != nil {
+			return r.handleStepError(ctx, jira, step.Name(), err)
+		}
+		if recordErr != nil {
+			return ctrl.Result{}, recordErr
+		}
+		if !result.Ready {
+			if result.Message != "" {
+				logger.Info(result.Message)
+			}
+			return ctrl.Result{RequeueAfter: waitingBackoff(r.stepStartedAt(jira, step.Name()), result.RequeueAfter)}, nil
+		}
+	}
+
+	metrics.SetPhase(jira.Name, metrics.PhaseReady)
+	// time to ready is recorded once, steps becoming ready again later on do not count
+	if jira.Status.ReadyAt == nil {
+		readyAt := metav1.NewTime(lastStepCompletion(jira))
+		jira.Status.ReadyAt = &readyAt
+		err := r.Status().Update(context.TODO(), jira)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	metrics.SetTimeToReady(jira.Name, jira.Status.ReadyAt.Sub(jira.CreationTimestamp.Time).Seconds())
+
+	// end of reconciliation loop
+	return ctrl.Result{RequeueAfter: 5 * time.Minute}, nil
+}
+
+// lastStepCompletion returns when the last of the steps of Jira became ready. For Jiras which were ready before
+// ReadyAt was recorded, it is the time of their latest transition to ready rather than the first one
+func lastStepCompletion(jira *appv1.Jira) time.Time {
+	readyAt := jira.CreationTimestamp.Time
+	for _, stepStatus := range jira.Status.Steps {
+		if stepStatus.CompletedAt != nil && stepStatus.CompletedAt.After(readyAt) {
+			readyAt = stepStatus.CompletedAt.Time
+		}
+	}
+	return readyAt
+}
+
+// handleStepError emits an event for a failed step and maps the error kind to a backoff
+func (r *JiraReconciler) handleStepError(ctx context.Context, jira *appv1.Jira, name string, stepErr error) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	kind := classifyError(stepErr)
+	message := name + ": " + stepErr.Error()
+	switch {
+	case kind == errorKindWaiting:
+		logger.Info("Waiting for dependency in step " + message)
+		r.Recorder.Event(jira, corev1.EventTypeNormal, string(kind), message)
+		return ctrl.Result{RequeueAfter: waitingBackoff(r.stepStartedAt(jira, name), 0)}, nil
+	case kind.stalls():
+		metrics.SetPhase(jira.Name, metrics.PhaseStalled)
+		logger.Error(stepErr, "Reconciliation stalled in step "+name, "kind", kind)
+		r.Recorder.Event(jira, corev1.EventTypeWarning, string(kind), message)
+		return ctrl.Result{}, r.setCondition(jira, appv1.ConditionStalled, metav1.ConditionTrue, string(kind), message)
+	default:
+		r.Recorder.Event(jira, corev1.EventTypeWarning, string(kind), message)
+		return ctrl.Result{}, stepErr
+	}
+}
+
+// stepStartedAt returns when a step last started working towards ready
+func (r *JiraReconciler) stepStartedAt(jira *appv1.Jira, name string) time.Time {
+	for _, stepStatus := range jira.Status.Steps {
+		if stepStatus.Name == name && stepStatus.StartedAt != nil {
+			return stepStatus.StartedAt.Time
+		}
+	}
+	return time.Now()
+}
+
+// recordStep sets the step condition and step status, and updates Jira status only when either changed
+func (r *JiraReconciler) recordStep(jira *appv1.Jira, name string, result stepResult, stepErr error) (err error) {
+	condition := metav1.Condition{
+		Type:               name + "Ready",
+		Status:             metav1.ConditionFalse,
+		Reason:             result.Reason,
+		Message:            result.Message,
+		ObservedGeneration: jira.Generation,
+	}
+	stepStatus := appv1.StepStatus{Name: name}
+	switch {
+	case stepErr != nil:
+		condition.Reason = string(classifyError(stepErr))
+		condition.Message = stepErr.Error()
+		stepStatus.Error = stepErr.Error()
+	case result.Ready:
+		condition.Status = metav1.ConditionTrue
+		if condition.Reason == "" {
+			condition.Reason = stepReasonReady
+		}
+		stepStatus.Ready = true
+	case condition.Reason == "":
+		condition.Reason = stepReasonInProgress
+	}
+
+	updated := false
+	existingCondition := meta.FindStatusCondition(jira.Status.Conditions, condition.Type)
+	// failed steps get an event from handleStepError
+	if stepErr == nil && (existingCondition == nil || existingCondition.Status != condition.Status || existingCondition.Reason != condition.Reason) {
+		eventType := corev1.EventTypeNormal
+		if existingCondition != nil && existingCondition.Status == metav1.ConditionTrue && condition.Status != metav1.ConditionTrue {
+			eventType = corev1.EventTypeWarning
+		}
+		message := condition.Message
+		if message == "" {
+			message = "Step " + name + " is " + condition.Reason
+		}
+		r.Recorder.Event(jira, eventType, condition.Reason, message)
+	}
+	if existingCondition == nil || existingCondition.Status != condition.Status || existingCondition.Reason != condition.Reason ||
+		existingCondition.Message != condition.Message || existingCondition.ObservedGeneration != condition.ObservedGeneration {
+		meta.SetStatusCondition(&jira.Status.Conditions, condition)
+		updated = true
+	}
+
+	// timestamps move only on transitions, so that an unchanged step does not update status on every reconcile
+	now := metav1.Now()
+	index := -1
+	for i := range jira.Status.Steps {
+		if jira.Status.Steps[i].Name == name {
+			index = i
+		}
+	}
+	if index == -1 {
+		jira.Status.Steps = append(jira.Status.Steps, stepStatus)
+		index = len(jira.Status.Steps) - 1
+		jira.Status.Steps[index].StartedAt = &now
+		updated = true
+	}
+	existingStep := &jira.Status.Steps[index]
+	if existingStep.Ready && !stepStatus.Ready {
+		existingStep.StartedAt = &now
+		existingStep.CompletedAt = nil
+		updated = true
+	}
+	if stepStatus.Ready && (!existingStep.Ready || existingStep.CompletedAt == nil) {
+		existingStep.CompletedAt = &now
+		metrics.ObserveStepCompletion(name, now.Sub(existingStep.StartedAt.Time).Seconds())
+		updated = true
+	}
+	if existingStep.CompletedAt != nil {
+		metrics.SetStepDuration(jira.Name, name, existingStep.CompletedAt.Sub(existingStep.StartedAt.Time).Seconds())
+		metrics.DeleteStepStarted(jira.Name, name)
+	} else {
+		metrics.SetStepDuration(jira.Name, name, now.Sub(existingStep.StartedAt.Time).Seconds())
+		metrics.SetStepStarted(jira.Name, name, float64(existingStep.StartedAt.Unix()))
+	}
+	if existingStep.Ready != stepStatus.Ready || existingStep.Error != stepStatus.Error {
+		existingStep.Ready = stepStatus.Ready
+		existingStep.Error = stepStatus.Error
+		updated = true
+	}
+
+	if !updated {
+		return nil
+	}
+	return r.Status().Update(context.TODO(), jira)
+}
@@ -0,0 +1,314 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// fakeStep returns fixed results and records the calls made to it in calls
+type fakeStep struct {
+	name     string
+	ensure   stepResult
+	ensureFn func(jira *appv1.Jira) error
+	ready    stepResult
+	readyErr error
+	calls    *[]string
+}
+
+func (s *fakeStep) Name() string {
+	return s.name
+}
+
+func (s *fakeStep) Ensure(ctx context.Context, jira *appv1.Jira) (stepResult, error) {
+	*s.calls = append(*s.calls, s.name+".Ensure")
+	if s.ensureFn != nil {
+		return s.ensure, s.ensureFn(jira)
+	}
+	return s.ensure, nil
+}
+
+func (s *fakeStep) Ready(ctx context.Context, jira *appv1.Jira) (stepResult, error) {
+	*s.calls = append(*s.calls, s.name+".Ready")
+	return s.ready, s.readyErr
+}
+
+func TestRunPipeline(t *testing.T) {
+	waiting := stepWaiting("WaitingForVolume", "Waiting for volume", 10*time.Second)
+	stalled := func(generation int64) func(*appv1.Jira) {
+		return func(jira *appv1.Jira) {
+			meta.SetStatusCondition(&jira.Status.Conditions, metav1.Condition{Type: appv1.ConditionStalled, Status: metav1.ConditionTrue,
+				Reason: string(errorKindSpec), ObservedGeneration: generation})
+		}
+	}
+	tests := []struct {
+		name         string
+		setup        func(*appv1.Jira)
+		second       fakeStep
+		wantCalls    []string
+		wantErr      bool
+		wantRequeue  time.Duration
+		wantTrue     []string
+		wantFalse    []string
+		wantNotFound []string
+	}{
+		{
+			name:        "all steps ready",
+			second:      fakeStep{ensure: stepDone(), ready: stepReady("Synced", "Synced")},
+			wantCalls:   []string{"First.Ensure", "First.Ready", "Second.Ensure", "Second.Ready"},
+			wantRequeue: 5 * time.Minute,
+			wantTrue:    []string{"FirstReady", "SecondReady"},
+		},
+		{
+			name:        "ensure waiting stops the pipeline",
+			second:      fakeStep{ensure: waiting},
+			wantCalls:   []string{"First.Ensure", "First.Ready", "Second.Ensure"},
+			wantRequeue: 10 * time.Second,
+			wantTrue:    []string{"FirstReady"},
+			wantFalse:   []string{"SecondReady"},
+		},
+		{
+			name:        "not ready stops the pipeline",
+			second:      fakeStep{ensure: stepDone(), ready: waiting},
+			wantCalls:   []string{"First.Ensure", "First.Ready", "Second.Ensure", "Second.Ready"},
+			wantRequeue: 10 * time.Second,
+			wantFalse:   []string{"SecondReady"},
+		},
+		{
+			name:      "transient error is returned",
+			second:    fakeStep{ensure: stepDone(), readyErr: errors.New("connection refused")},
+			wantCalls: []string{"First.Ensure", "First.Ready", "Second.Ensure", "Second.Ready"},
+			wantErr:   true,
+			wantFalse: []string{"SecondReady"},
+		},
+		{
+			name:        "waiting error is requeued without error",
+			second:      fakeStep{ensureFn: func(*appv1.Jira) error { return newWaitingError("cluster is not registered") }},
+			wantCalls:   []string{"First.Ensure", "First.Ready", "Second.Ensure"},
+			wantRequeue: minWaitingBackoff,
+			wantFalse:   []string{"SecondReady"},
+		},
+		{
+			name:      "spec error stalls",
+			second:    fakeStep{ensureFn: func(*appv1.Jira) error { return newSpecError("invalid spec") }},
+			wantCalls: []string{"First.Ensure", "First.Ready", "Second.Ensure"},
+			wantTrue:  []string{appv1.ConditionStalled},
+			wantFalse: []string{"SecondReady"},
+		},
+		{
+			name:         "stalled until generation changes",
+			setup:        stalled(1),
+			wantTrue:     []string{appv1.ConditionStalled},
+			wantNotFound: []string{"FirstReady"},
+		},
+		{
+			name:        "stalled retried after spec changed",
+			setup:       stalled(0),
+			second:      fakeStep{ensure: stepDone(), ready: stepDone()},
+			wantCalls:   []string{"First.Ensure", "First.Ready", "Second.Ensure", "Second.Ready"},
+			wantRequeue: 5 * time.Minute,
+			wantFalse:   []string{appv1.ConditionStalled},
+		},
+		{
+			name:         "paused by spec",
+			setup:        func(jira *appv1.Jira) { jira.Spec.Paused = true },
+			wantTrue:     []string{appv1.ConditionPaused},
+			wantNotFound: []string{"FirstReady"},
+		},
+		{
+			name:         "paused by annotation",
+			setup:        func(jira *appv1.Jira) { jira.Annotations = map[string]string{appv1.PausedAnnotation: "true"} },
+			wantTrue:     []string{appv1.ConditionPaused},
+			wantNotFound: []string{"FirstReady"},
+		},
+		{
+			name:        "maintenance keeps running steps",
+			setup:       func(jira *appv1.Jira) { jira.Spec.Maintenance = true },
+			second:      fakeStep{ensure: stepDone(), ready: stepDone()},
+			wantCalls:   []string{"First.Ensure", "First.Ready", "Second.Ensure", "Second.Ready"},
+			wantRequeue: 5 * time.Minute,
+			wantTrue:    []string{appv1.ConditionMaintenance, "SecondReady"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			jira := newTestJira()
+			if test.setup != nil {
+				test.setup(jira)
+			}
+			r, jira := newTestReconciler(t, jira)
+			var calls []string
+			first := &fakeStep{name: "First", ensure: stepDone(), ready: stepDone(), calls: &calls}
+			second := test.second
+			second.name = "Second"
+			second.calls = &calls
+
+			result, err := r.runPipeline(context.TODO(), jira, []Step{first, &second})
+			if (err != nil) != test.wantErr {
+				t.Fatalf("runPipeline() error = %v, want error %t", err, test.wantErr)
+			}
+			if len(calls) != len(test.wantCalls) {
+				t.Fatalf("calls = %v, want %v", calls, test.wantCalls)
+			}
+			for i := range calls {
+				if calls[i] != test.wantCalls[i] {
+					t.Fatalf("calls = %v, want %v", calls, test.wantCalls)
+				}
+			}
+			if result.RequeueAfter < test.wantRequeue || result.RequeueAfter > test.wantRequeue+time.Second {
+				t.Errorf("RequeueAfter = %s, want %s", result.RequeueAfter, test.wantRequeue)
+			}
+
+			// conditions are checked on the stored Jira, so that a status which was not written fails the test
+			stored := &appv1.Jira{}
+			if err := r.Get(context.TODO(), client.ObjectKeyFromObject(jira), stored); err != nil {
+				t.Fatal(err)
+			}
+			for _, conditionType := range test.wantTrue {
+				if !meta.IsStatusConditionTrue(stored.Status.Conditions, conditionType) {
+					t.Errorf("condition %s is not true: %v", conditionType, stored.Status.Conditions)
+				}
+			}
+			for _, conditionType := range test.wantFalse {
+				if !meta.IsStatusConditionFalse(stored.Status.Conditions, conditionType) {
+					t.Errorf("condition %s is not false: %v", conditionType, stored.Status.Conditions)
+				}
+			}
+			for _, conditionType := range test.wantNotFound {
+				if meta.FindStatusCondition(stored.Status.Conditions, conditionType) != nil {
+					t.Errorf("condition %s is set: %v", conditionType, stored.Status.Conditions)
+				}
+			}
+		})
+	}
+}
+
+func TestRecordStep(t *testing.T) {
+	waiting := stepWaiting("WaitingForVolume", "Waiting for volume", 0)
+	tests := []struct {
+		name          string
+		results       []stepResult
+		wantReady     bool
+		wantCompleted bool
+		wantReason    string
+		wantUpdates   int
+		wantWarning   bool
+	}{
+		{
+			name:        "waiting step is started",
+			results:     []stepResult{waiting},
+			wantReason:  "WaitingForVolume",
+			wantUpdates: 1,
+		},
+		{
+			name:        "unchanged step does not update status",
+			results:     []stepResult{waiting, waiting, waiting},
+			wantReason:  "WaitingForVolume",
+			wantUpdates: 1,
+		},
+		{
+			name:          "ready step is completed",
+			results:       []stepResult{waiting, stepDone()},
+			wantReady:     true,
+			wantCompleted: true,
+			wantReason:    stepReasonReady,
+			wantUpdates:   2,
+		},
+		{
+			name:        "step which is no longer ready is restarted with a warning",
+			results:     []stepResult{stepDone(), {}},
+			wantReason:  stepReasonInProgress,
+			wantUpdates: 2,
+			wantWarning: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r, jira := newTestReconciler(t, newTestJira())
+			resourceVersion := jira.ResourceVersion
+			updates := 0
+			for _, result := range test.results {
+				if err := r.recordStep(jira, "Volume", result, nil); err != nil {
+					t.Fatal(err)
+				}
+				if jira.ResourceVersion != resourceVersion {
+					resourceVersion = jira.ResourceVersion
+					updates++
+				}
+			}
+			if updates != test.wantUpdates {
+				t.Errorf("status updates = %d, want %d", updates, test.wantUpdates)
+			}
+
+			if len(jira.Status.Steps) != 1 {
+				t.Fatalf("steps = %v, want one step", jira.Status.Steps)
+			}
+			stepStatus := jira.Status.Steps[0]
+			if stepStatus.Ready != test.wantReady || (stepStatus.CompletedAt != nil) != test.wantCompleted || stepStatus.StartedAt == nil {
+				t.Errorf("step = %+v, want ready %t and completed %t", stepStatus, test.wantReady, test.wantCompleted)
+			}
+			condition := meta.FindStatusCondition(jira.Status.Conditions, "VolumeReady")
+			if condition == nil || condition.Reason != test.wantReason {
+				t.Errorf("condition = %v, want reason %s", condition, test.wantReason)
+			}
+
+			warning := false
+			events := r.Recorder.(*record.FakeRecorder).Events
+			for len(events) > 0 {
+				if event := <-events; len(event) > 7 && event[:7] == "Warning" {
+					warning = true
+				}
+			}
+			if warning != test.wantWarning {
+				t.Errorf("warning event = %t, want %t", warning, test.wantWarning)
+			}
+		})
+	}
+}
+
+func TestRecordStepError(t *testing.T) {
+	r, jira := newTestReconciler(t, newTestJira())
+	err := r.recordStep(jira, "Volume", stepResult{}, newSpecError("invalid size"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	condition := meta.FindStatusCondition(jira.Status.Conditions, "VolumeReady")
+	if condition == nil || condition.Reason != string(errorKindSpec) || condition.Message != "invalid size" {
+		t.Errorf("condition = %v, want reason %s", condition, errorKindSpec)
+	}
+	if jira.Status.Steps[0].Error != "invalid size" {
+		t.Errorf("step error = %q, want invalid size", jira.Status.Steps[0].Error)
+	}
+}
+
+func TestReadyAtIsRecordedOnce(t *testing.T) {
+	r, jira := newTestReconciler(t, newTestJira())
+	var calls []string
+	steps := []Step{&fakeStep{name: "First", ensure: stepDone(), ready: stepDone(), calls: &calls}}
+	_, err := r.runPipeline(context.TODO(), jira, steps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if jira.Status.ReadyAt == nil {
+		t.Fatal("ReadyAt is not recorded once all steps are ready")
+	}
+	readyAt := *jira.Status.ReadyAt
+
+	// the step becomes ready again an hour later
+	completedAt := metav1.NewTime(readyAt.Add(time.Hour))
+	jira.Status.Steps[0].CompletedAt = &completedAt
+	_, err = r.runPipeline(context.TODO(), jira, steps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !jira.Status.ReadyAt.Equal(&readyAt) {
+		t.Errorf("ReadyAt = %s, want first ready time %s", jira.Status.ReadyAt, readyAt)
+	}
+}
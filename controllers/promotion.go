@@ -0,0 +1,262 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+	"github.com/atlassian-labs/jira-operator/backup"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"time"
+)
+
+const (
+	defaultHealthTimeout = 30 * time.Minute
+	// rolloutHistoryLimit is the number of finished rollouts kept in Jira status
+	rolloutHistoryLimit = 10
+)
+
+// getSpecRelease returns the chart version and values revision requested in Jira spec
+func getSpecRelease(jira appv1.Jira) appv1.Release {
+	return appv1.Release{ChartVersion: jira.Spec.ArgoCD.HelmChart.Version, GitRevision: jira.Spec.ArgoCD.HelmValues.GitRevision}
+}
+
+// getReleasedJira returns Jira with the chart version and values revision to deploy. With promotion enabled they are
+// the ones of the previous release while snapshots are taken, and after a rollout was rolled back. With blue/green
+// deployment, the stack of Jira keeps its release and new ones are deployed to other stacks
+func getReleasedJira(jira *appv1.Jira) *appv1.Jira {
+	release := jira.Status.Promotion.Release
+	if (!jira.Spec.Promotion.Enabled && !jira.Spec.BlueGreen.Enabled) || release == (appv1.Release{}) || release == getSpecRelease(*jira) {
+		return jira
+	}
+	released := jira.DeepCopy()
+	released.Spec.ArgoCD.HelmChart.Version = release.ChartVersion
+	released.Spec.ArgoCD.HelmValues.GitRevision = release.GitRevision
+	return released
+}
+
+// ensurePromotion starts a rollout when the chart version or values revision in Jira spec changed, and snapshots database
+// and shared home before the new release is deployed. It returns a not ready result until the release can be deployed
+func (r *JiraReconciler) ensurePromotion(ctx context.Context, jira *appv1.Jira) (stepResult, error) {
+	logger := log.FromContext(ctx)
+	if !jira.Spec.Promotion.Enabled {
+		return stepDone(), nil
+	}
+	promotion := &jira.Status.Promotion
+	target := getSpecRelease(*jira)
+	rollout := promotion.Rollout
+
+	// the first release, or the one deployed when promotion gets enabled, is not rolled out
+	if promotion.Release == (appv1.Release{}) && target != (appv1.Release{}) {
+		promotion.Release = target
+		return stepDone(), r.Status().Update(context.TODO(), jira)
+	}
+	// a failed rollout is started again once spec changes, as reconciliation stalls until then
+	if rollout != nil && rollout.To == target && rollout.Phase != appv1.RolloutPhaseFailed {
+		if rollout.Phase == appv1.RolloutPhaseSnapshotting {
+			return r.snapshotBeforeRollout(ctx, jira)
+		}
+		return stepDone(), nil
+	}
+	if promotion.Release == target {
+		return stepDone(), nil
+	}
+
+	// a rollout replacing one in progress starts from the same release, which is the last one known to be healthy
+	from := promotion.Release
+	if rollout != nil && (rollout.Phase == appv1.RolloutPhaseSnapshotting || rollout.Phase == appv1.RolloutPhaseRollingOut) {
+		from = rollout.From
+		finishRollout(jira, appv1.RolloutPhaseFailed, "Replaced by rollout of "+target.String())
+	}
+	logger.Info("Starting rollout of " + target.String())
+	r.Recorder.Event(jira, corev1.EventTypeNormal, "RolloutStarted", "Rolling out "+target.String()+" from "+from.String())
+	promotion.Rollout = &appv1.Rollout{From: from, To: target, Phase: appv1.RolloutPhaseSnapshotting}
+	if jira.Spec.Promotion.SkipSnapshots {
+		startRollingOut(jira)
+		return stepDone(), r.Status().Update(context.TODO(), jira)
+	}
+	return r.snapshotBeforeRollout(ctx, jira)
+}
+
+// snapshotBeforeRollout snapshots database and shared home, and starts rolling out once all snapshots are available
+func (r *JiraReconciler) snapshotBeforeRollout(ctx context.Context, jira *appv1.Jira) (stepResult, error) {
+	logger := log.FromContext(ctx)
+	rollout := jira.Status.Promotion.Rollout
+	databaseSnapshotId, sharedHomeSnapshotId := rollout.DatabaseSnapshotId, rollout.SharedHomeSnapshotId
+	available, err := r.takeSnapshots(ctx, jira, &rollout.DatabaseSnapshotId, &rollout.SharedHomeSnapshotId)
+	if errors.Is(err, backup.ErrSnapshotFailed) {
+		finishRollout(jira, appv1.RolloutPhaseFailed, err.Error())
+		statusErr := r.Status().Update(context.TODO(), jira)
+		if statusErr != nil {
+			return stepResult{}, statusErr
+		}
+		return stepResult{}, newPermanentError("rollout of %s failed: %w", rollout.To.String(), err)
+	}
+	// snapshot IDs are recorded even when taking another snapshot failed, so that a snapshot is not taken twice
+	if rollout.DatabaseSnapshotId != databaseSnapshotId || rollout.SharedHomeSnapshotId != sharedHomeSnapshotId {
+		r.Recorder.Event(jira, corev1.EventTypeNormal, "CreatingSnapshots", "Creating snapshots before rolling out "+rollout.To.String())
+		statusErr := r.Status().Update(context.TODO(), jira)
+		if statusErr != nil {
+			return stepResult{}, statusErr
+		}
+	}
+	if err != nil {
+		return stepResult{}, err
+	}
+	if !available {
+		return stepWaiting("CreatingSnapshots", "Waiting for snapshots before rolling out "+rollout.To.String(), time.Minute), nil
+	}
+
+	logger.Info("Snapshots are available, rolling out " + rollout.To.String())
+	r.Recorder.Event(jira, corev1.EventTypeNormal, "RollingOut", "Snapshots are available, rolling out "+rollout.To.String())
+	startRollingOut(jira)
+	return stepDone(), r.Status().Update(context.TODO(), jira)
+}
+
+// takeSnapshots creates the snapshots of database and shared home of a Jira whose IDs are not set yet, and returns
+// whether all of them are available. Shared home on EFS is not snapshotted, it is backed up with AWS Backup
+func (r *JiraReconciler) takeSnapshots(ctx context.Context, source *appv1.Jira, databaseSnapshotId *string, sharedHomeSnapshotId *string) (available bool, err error) {
+	logger := log.FromContext(ctx)
+	snapshotSharedHome := source.Spec.SharedFS.GetType() == appv1.SharedFSTypeEbs || source.Spec.SharedFS.GetType() == appv1.SharedFSTypeFsx
+
+	if *databaseSnapshotId == "" || (snapshotSharedHome && *sharedHomeSnapshotId == "") {
+		name := backup.GetSnapshotName(*source, time.Now())
+		if *databaseSnapshotId == "" {
+			logger.Info("Creating RDS snapshot " + name)
+			*databaseSnapshotId, err = backup.CreateDatabaseSnapshot(*source, name)
+			if err != nil {
+				return false, err
+			}
+		}
+		if snapshotSharedHome && *sharedHomeSnapshotId == "" {
+			logger.Info("Creating shared home snapshot " + name)
+			*sharedHomeSnapshotId, err = r.createSharedHomeSnapshot(ctx, source, name)
+		}
+		return false, err
+	}
+
+	available, err = backup.IsDatabaseSnapshotAvailable(*source, *databaseSnapshotId)
+	if err == nil && available && *sharedHomeSnapshotId != "" {
+		available, err = r.isSharedHomeSnapshotAvailable(source, *sharedHomeSnapshotId)
+	}
+	return available, err
+}
+
+// checkRollout completes a rollout once Jira is healthy with the new release, and rolls back to the previous release when
+// Jira is not healthy within the health timeout. It returns stepDone when there is no rollout in progress
+func (r *JiraReconciler) checkRollout(ctx context.Context, jira *appv1.Jira, appStatus appv1.AppStatus) (stepResult, error) {
+	logger := log.FromContext(ctx)
+	rollout := jira.Status.Promotion.Rollout
+	if !jira.Spec.Promotion.Enabled || rollout == nil {
+		return stepDone(), nil
+	}
+	if rollout.Phase == appv1.RolloutPhaseRolledBack && rollout.To == getSpecRelease(*jira) && appStatus.Health == healthStatusHealthy {
+		return stepReady("RolledBack", "Rollout of "+rollout.To.String()+" was rolled back to "+rollout.From.String()+": "+rollout.Message), nil
+	}
+	if rollout.Phase != appv1.RolloutPhaseRollingOut {
+		return stepDone(), nil
+	}
+
+	// Argo CD may report the previous release as healthy until it refreshes the application, so the chart version is checked
+	released := appStatus.Sync == syncStatusSynced && appStatus.Health == healthStatusHealthy &&
+		(rollout.To.ChartVersion == "" || containsString(appStatus.Revisions, rollout.To.ChartVersion))
+	if released {
+		logger.Info("Rollout of " + rollout.To.String() + " succeeded")
+		r.Recorder.Event(jira, corev1.EventTypeNormal, "RolloutSucceeded", "Jira is healthy with "+rollout.To.String())
+		finishRollout(jira, appv1.RolloutPhaseSucceeded, "Jira is healthy")
+		return stepDone(), r.Status().Update(context.TODO(), jira)
+	}
+
+	healthTimeout, err := getHealthTimeout(*jira)
+	if err != nil {
+		return stepResult{}, err
+	}
+	deadline := rollout.StartedAt.Add(healthTimeout)
+	if time.Now().Before(deadline) {
+		return stepWaiting("RollingOut", fmt.Sprintf("Waiting until %s for Jira to be healthy with %s", deadline.UTC().Format(time.RFC3339), rollout.To.String()), time.Minute), nil
+	}
+
+	message := fmt.Sprintf("Jira was not healthy within %s, application is %s and %s", healthTimeout, appStatus.Sync, appStatus.Health)
+	if len(appStatus.Conditions) > 0 {
+		message += ". " + appStatus.Conditions[0].Type + ": " + appStatus.Conditions[0].Message
+	}
+	logger.Info("Rolling back to " + rollout.From.String() + ": " + message)
+	r.Recorder.Event(jira, corev1.EventTypeWarning, "RolledBack", "Rolling back to "+rollout.From.String()+": "+message)
+	jira.Status.Promotion.Release = rollout.From
+	finishRollout(jira, appv1.RolloutPhaseRolledBack, message)
+	err = r.Status().Update(context.TODO(), jira)
+	return stepWaiting("RollingBack", "Rolling back to "+rollout.From.String(), 5*time.Second), err
+}
+
+// startRollingOut deploys the release of the current rollout, the health timeout starts from then
+func startRollingOut(jira *appv1.Jira) {
+	now := metav1.Now()
+	rollout := jira.Status.Promotion.Rollout
+	rollout.Phase = appv1.RolloutPhaseRollingOut
+	rollout.StartedAt = &now
+	jira.Status.Promotion.Release = rollout.To
+}
+
+// finishRollout records the outcome of the current rollout and adds it to rollout history
+func finishRollout(jira *appv1.Jira, phase string, message string) {
+	now := metav1.Now()
+	rollout := jira.Status.Promotion.Rollout
+	rollout.Phase = phase
+	rollout.Message = message
+	rollout.CompletedAt = &now
+	history := append([]appv1.Rollout{*rollout}, jira.Status.Promotion.History...)
+	if len(history) > rolloutHistoryLimit {
+		history = history[:rolloutHistoryLimit]
+	}
+	jira.Status.Promotion.History = history
+}
+
+func getHealthTimeout(jira appv1.Jira) (time.Duration, error) {
+	if jira.Spec.Promotion.HealthTimeout == "" {
+		return defaultHealthTimeout, nil
+	}
+	healthTimeout, err := time.ParseDuration(jira.Spec.Promotion.HealthTimeout)
+	if err != nil {
+		return 0, newSpecError("invalid spec.promotion.healthTimeout: %w", err)
+	}
+	return healthTimeout, nil
+}
+
+// createSharedHomeSnapshot snapshots the EBS or FSx volume of shared home
+func (r *JiraReconciler) createSharedHomeSnapshot(ctx context.Context, jira *appv1.Jira, name string) (snapshotId string, err error) {
+	volumeId := getSharedHomeVolumeId(*jira)
+	if volumeId == "" {
+		return "", newWaitingError("shared home volume is not bound yet")
+	}
+	if jira.Spec.SharedFS.GetType() == appv1.SharedFSTypeEbs {
+		return backup.CreateEbsSnapshot(*jira, volumeId, name)
+	}
+	return backup.CreateFsxSnapshot(*jira, volumeId, name)
+}
+
+// getSharedHomeVolumeId returns the AWS ID of the EBS or FSx volume of shared home.
+// FsxId is the CSI volume handle of shared home, which is the FSx volume ID
+func getSharedHomeVolumeId(jira appv1.Jira) string {
+	if jira.Spec.SharedFS.GetType() == appv1.SharedFSTypeEbs {
+		return jira.Status.SharedFilesystemStatus.EbsId
+	}
+	return jira.Status.SharedFilesystemStatus.FsxId
+}
+
+func (r *JiraReconciler) isSharedHomeSnapshotAvailable(jira *appv1.Jira, snapshotId string) (available bool, err error) {
+	if jira.Spec.SharedFS.GetType() == appv1.SharedFSTypeEbs {
+		return backup.IsEbsSnapshotCompleted(*jira, snapshotId)
+	}
+	return backup.IsFsxSnapshotAvailable(*jira, snapshotId)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,33 @@
+package controllers
+
+import (
+	"testing"
+
+	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+)
+
+func TestGetSharedHomeVolumeId(t *testing.T) {
+	tests := []struct {
+		name     string
+		sharedFS appv1.SharedFS
+		want     string
+	}{
+		{"EBS", appv1.SharedFS{Type: appv1.SharedFSTypeEbs}, "vol-0123456789abcdef0"},
+		{"EBS restored from a snapshot", appv1.SharedFS{Ebs: appv1.EbsSpec{SnapshotId: "snap-1"}}, "vol-0123456789abcdef0"},
+		{"FSx", appv1.SharedFS{Type: appv1.SharedFSTypeFsx}, "fsvol-0123456789abcdef0"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			jira := *newTestJira()
+			jira.Spec.SharedFS = test.sharedFS
+			jira.Status.SharedFilesystemStatus = appv1.SharedFilesystemStatus{
+				EbsId: "vol-0123456789abcdef0",
+				// CSI volume handle of the FSx persistent volume
+				FsxId: "fsvol-0123456789abcdef0",
+			}
+			if got := getSharedHomeVolumeId(jira); got != test.want {
+				t.Errorf("getSharedHomeVolumeId() = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
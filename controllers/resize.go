@@ -0,0 +1,232 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+	"github.com/atlassian-labs/jira-operator/ebs"
+	"github.com/atlassian-labs/jira-operator/k8s"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"strconv"
+)
+
+const (
+	resizeStatusModifyingVolume     = "ModifyingVolume"
+	resizeStatusGrowingFileSystem   = "GrowingFileSystem"
+	resizeStatusResized             = "Resized"
+	resizeStatusShrinkRefused       = "ShrinkRefused"
+	resizeStatusExpansionNotAllowed = "ExpansionNotAllowed"
+)
+
+// getSharedHomeSizeChange compares requested shared home size with the size it was provisioned or expanded to.
+// Volumes can only grow, so a smaller size is refused and reported in status
+func (r *JiraReconciler) getSharedHomeSizeChange(ctx context.Context, jira *appv1.Jira) (grow bool, err error) {
+	logger := log.FromContext(ctx)
+	requestedSize := jira.Spec.SharedFS.VolumeSize
+	currentSize := jira.Status.SharedFilesystemStatus.VolumeSize
+
+	// nothing to compare to right after shared home is provisioned
+	if currentSize == 0 {
+		return false, r.updateResizeStatus(jira, "", requestedSize)
+	}
+	if requestedSize < currentSize {
+		logger.Info(fmt.Sprintf("Refusing to shrink shared home from %dGi to %dGi", currentSize, requestedSize))
+		return false, r.updateResizeStatus(jira, resizeStatusShrinkRefused, currentSize)
+	}
+	if requestedSize == currentSize {
+		switch jira.Status.SharedFilesystemStatus.ResizeStatus {
+		// a modification refused by AWS is given up when the size is reverted
+		case resizeStatusShrinkRefused, resizeStatusExpansionNotAllowed, resizeStatusModifyingVolume:
+			return false, r.updateResizeStatus(jira, "", currentSize)
+		}
+		return false, nil
+	}
+	return true, nil
+}
+
+// getProvisionedSize returns the size in Gi shared home volumes are applied with, which is the size they were
+// provisioned or last expanded to. A raised SharedFS.VolumeSize only reaches them through the resize below
+func getProvisionedSize(jira *appv1.Jira) int64 {
+	if jira.Status.SharedFilesystemStatus.VolumeSize == 0 {
+		return jira.Spec.SharedFS.VolumeSize
+	}
+	return jira.Status.SharedFilesystemStatus.VolumeSize
+}
+
+// getEbsVolumeSize returns the size the EBS volume is applied with, which is the requested size once the resize
+// below started modifying it
+func getEbsVolumeSize(jira *appv1.Jira) int64 {
+	switch jira.Status.SharedFilesystemStatus.ResizeStatus {
+	case resizeStatusModifyingVolume, resizeStatusGrowingFileSystem:
+		return jira.Spec.SharedFS.VolumeSize
+	}
+	return getProvisionedSize(jira)
+}
+
+// withVolumeSize returns a copy of Jira rendering shared home volumes with a size
+func withVolumeSize(jira *appv1.Jira, size int64) appv1.Jira {
+	sized := *jira.DeepCopy()
+	sized.Spec.SharedFS.VolumeSize = size
+	return sized
+}
+
+// resizeEbsSharedHome grows EBS volume through crossplane, waits for AWS to modify it, then grows nfs server and
+// shared home PVs and PVCs and finally the filesystem exported by nfs server. It returns true once shared home
+// has the requested size, or when it cannot be expanded
+func (r *JiraReconciler) resizeEbsSharedHome(ctx context.Context, jira *appv1.Jira, ebsVolumeId string, pvNames []string, pvcs []corev1.PersistentVolumeClaim, nfsServerPodName string, namespace string) (resized bool, err error) {
+	logger := log.FromContext(ctx)
+	grow, err := r.getSharedHomeSizeChange(ctx, jira)
+	if err != nil || !grow {
+		return !grow, err
+	}
+	requestedSize := jira.Spec.SharedFS.VolumeSize
+
+	switch jira.Status.SharedFilesystemStatus.ResizeStatus {
+	case resizeStatusModifyingVolume:
+		modified, err := ebs.IsVolumeModified(*jira, ebsVolumeId, requestedSize)
+		if errors.Is(err, ebs.ErrModificationFailed) {
+			return false, newPermanentError("%w", err)
+		} else if errors.Is(err, ebs.ErrModificationRateExceeded) {
+			// surfaced rather than polled for, as AWS refuses the modification crossplane requests for up to 6 hours
+			return false, newSpecError("%w, revert spec.sharedFs.volumeSize to %dGi or change it again once it can be modified",
+				err, jira.Status.SharedFilesystemStatus.VolumeSize)
+		} else if err != nil || !modified {
+			return false, err
+		}
+
+		capacity := resource.MustParse(strconv.Itoa(int(requestedSize)) + "Gi")
+		for _, pvName := range pvNames {
+			pv, err := r.getPvByName(pvName)
+			if err != nil {
+				return false, err
+			}
+			currentCapacity := pv.Spec.Capacity[corev1.ResourceStorage]
+			if currentCapacity.Cmp(capacity) < 0 {
+				logger.Info(fmt.Sprintf("Expanding PersistentVolume %s to %dGi", pvName, requestedSize))
+				pv.Spec.Capacity[corev1.ResourceStorage] = capacity
+				err = r.Update(context.TODO(), &pv)
+				if err != nil {
+					return false, err
+				}
+			}
+		}
+		for _, pvc := range pvcs {
+			err = r.expandPersistentVolumeClaim(ctx, pvc, requestedSize)
+			if err != nil {
+				return false, err
+			}
+		}
+		return false, r.updateResizeStatus(jira, resizeStatusGrowingFileSystem, jira.Status.SharedFilesystemStatus.VolumeSize)
+
+	case resizeStatusGrowingFileSystem:
+		var nfsServerPod corev1.Pod
+		err = r.Get(context.TODO(), client.ObjectKey{Name: nfsServerPodName, Namespace: namespace}, &nfsServerPod)
+		if err != nil {
+			return false, err
+		}
+		nfsResizeJob := k8s.GetNfsResizeJob(*jira, namespace, nfsServerPod.Spec.NodeName, requestedSize)
+		err = r.create(ctx, jira, &nfsResizeJob)
+		if err != nil {
+			return false, err
+		}
+		nfsResizeJobFailed, err := r.getJobFailed(nfsResizeJob)
+		if err != nil {
+			return false, err
+		}
+		if nfsResizeJobFailed {
+			return false, r.retryFailedJob(ctx, jira, nfsResizeJob, "NFS resize")
+		}
+		nfsResizeJobSucceededReplicas, err := r.getJobSucceededReplicas(nfsResizeJob)
+		if err != nil || nfsResizeJobSucceededReplicas < 1 {
+			return false, err
+		}
+		logger.Info(fmt.Sprintf("Shared home expanded to %dGi", requestedSize))
+		r.Recorder.Event(jira, corev1.EventTypeNormal, "JobSucceeded", "NFS resize job "+nfsResizeJob.Name+" succeeded")
+		return true, r.updateResizeStatus(jira, resizeStatusResized, requestedSize)
+	}
+
+	// claims which cannot be expanded would fail to be applied at the requested size, so the volume is left as is
+	expandable, err := r.canExpandClaims(ctx, jira, pvcs)
+	if err != nil || !expandable {
+		return true, err
+	}
+	// the EBS volume is applied at the requested size from now on, which makes crossplane modify it
+	logger.Info(fmt.Sprintf("Expanding EBS volume %s to %dGi", ebsVolumeId, requestedSize))
+	return false, r.updateResizeStatus(jira, resizeStatusModifyingVolume, jira.Status.SharedFilesystemStatus.VolumeSize)
+}
+
+// resizeSharedHomeClaim grows shared home PVC of dynamically provisioned FSx or EFS volumes
+func (r *JiraReconciler) resizeSharedHomeClaim(ctx context.Context, jira *appv1.Jira, pvc corev1.PersistentVolumeClaim) (err error) {
+	grow, err := r.getSharedHomeSizeChange(ctx, jira)
+	if err != nil || !grow {
+		return err
+	}
+	expandable, err := r.canExpandClaims(ctx, jira, []corev1.PersistentVolumeClaim{pvc})
+	if err != nil || !expandable {
+		return err
+	}
+	// the claim is applied at the recorded size, so it is recorded first and expanded again on the next reconcile if this fails
+	err = r.updateResizeStatus(jira, resizeStatusResized, jira.Spec.SharedFS.VolumeSize)
+	if err != nil {
+		return err
+	}
+	return r.expandPersistentVolumeClaim(ctx, pvc, jira.Spec.SharedFS.VolumeSize)
+}
+
+// canExpandClaims returns whether the storage classes of all claims allow volume expansion. When one does not,
+// the resize is refused and reported in status until the storage class or the requested size changes
+func (r *JiraReconciler) canExpandClaims(ctx context.Context, jira *appv1.Jira, pvcs []corev1.PersistentVolumeClaim) (expandable bool, err error) {
+	logger := log.FromContext(ctx)
+	for _, pvc := range pvcs {
+		storageClassName := ""
+		if pvc.Spec.StorageClassName != nil {
+			storageClassName = *pvc.Spec.StorageClassName
+		}
+		storageClass := storagev1.StorageClass{}
+		if storageClassName != "" {
+			err = r.Get(context.TODO(), client.ObjectKey{Name: storageClassName}, &storageClass)
+			if client.IgnoreNotFound(err) != nil {
+				return false, err
+			}
+		}
+		if storageClass.AllowVolumeExpansion == nil || !*storageClass.AllowVolumeExpansion {
+			logger.Info("Storage class " + storageClassName + " of PVC " + pvc.Name + " does not allow volume expansion, refusing to expand shared home")
+			if jira.Status.SharedFilesystemStatus.ResizeStatus != resizeStatusExpansionNotAllowed {
+				r.Recorder.Event(jira, corev1.EventTypeWarning, "ExpansionNotAllowed", "Storage class "+storageClassName+" of PVC "+pvc.Name+" does not allow volume expansion")
+			}
+			return false, r.updateResizeStatus(jira, resizeStatusExpansionNotAllowed, jira.Status.SharedFilesystemStatus.VolumeSize)
+		}
+	}
+	return true, nil
+}
+
+// expandPersistentVolumeClaim raises PVC storage request, its storage class is checked by canExpandClaims beforehand
+func (r *JiraReconciler) expandPersistentVolumeClaim(ctx context.Context, pvc corev1.PersistentVolumeClaim, size int64) (err error) {
+	logger := log.FromContext(ctx)
+	err = r.Get(context.TODO(), client.ObjectKey{Name: pvc.Name, Namespace: pvc.Namespace}, &pvc)
+	if err != nil {
+		return err
+	}
+	requested := resource.MustParse(strconv.Itoa(int(size)) + "Gi")
+	currentRequest := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	if currentRequest.Cmp(requested) >= 0 {
+		return nil
+	}
+	logger.Info(fmt.Sprintf("Expanding PersistentVolumeClaim %s to %dGi", pvc.Name, size))
+	pvc.Spec.Resources.Requests[corev1.ResourceStorage] = requested
+	return r.Update(context.TODO(), &pvc)
+}
+
+func (r *JiraReconciler) updateResizeStatus(jira *appv1.Jira, resizeStatus string, volumeSize int64) (err error) {
+	if jira.Status.SharedFilesystemStatus.ResizeStatus == resizeStatus && jira.Status.SharedFilesystemStatus.VolumeSize == volumeSize {
+		return nil
+	}
+	jira.Status.SharedFilesystemStatus.ResizeStatus = resizeStatus
+	jira.Status.SharedFilesystemStatus.VolumeSize = volumeSize
+	return r.Status().Update(context.TODO(), jira)
+}
@@ -0,0 +1,19 @@
+package controllers
+
+import (
+	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+)
+
+const sharedHomeStepName = "SharedHome"
+
+// sharedHomeStep returns the step provisioning shared home with the filesystem type requested in Jira spec
+func (r *JiraReconciler) sharedHomeStep(jira *appv1.Jira) Step {
+	switch jira.Spec.SharedFS.GetType() {
+	case appv1.SharedFSTypeEbs:
+		return &ebsSharedHomeStep{r}
+	case appv1.SharedFSTypeFsx:
+		return &fsxSharedHomeStep{r}
+	default:
+		return &efsSharedHomeStep{r}
+	}
+}
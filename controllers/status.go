@@ -5,6 +5,7 @@ import (
 	database "github.com/crossplane-contrib/provider-aws/apis/database/v1beta1"
 	ec2 "github.com/crossplane-contrib/provider-aws/apis/ec2/v1alpha1"
 	efs "github.com/crossplane-contrib/provider-aws/apis/efs/v1alpha1"
+	snapshot "github.com/kubernetes-csi/external-snapshotter/client/v6/apis/volumesnapshot/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -41,13 +42,30 @@ func (r *JiraReconciler) getMountTargetStatus(mountTarget efs.MountTarget, rdsOb
 	return status, nil
 }
 
-func (r *JiraReconciler) getFilesystemId(fileSystem efs.FileSystem, rdsObjKey client.ObjectKey) (id *string, err error) {
+func (r *JiraReconciler) getFilesystemStatus(fileSystem efs.FileSystem, rdsObjKey client.ObjectKey) (id *string, lifeCycleState string, err error) {
 	err = r.Get(context.TODO(), rdsObjKey, &fileSystem)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	id = fileSystem.Status.AtProvider.FileSystemID
-	return id, nil
+	if fileSystem.Status.AtProvider.LifeCycleState != nil {
+		lifeCycleState = *fileSystem.Status.AtProvider.LifeCycleState
+	}
+	return id, lifeCycleState, nil
+}
+
+func (r *JiraReconciler) getAccessPointStatus(accessPoint efs.AccessPoint, objKey client.ObjectKey) (id string, status string, err error) {
+	err = r.Get(context.TODO(), objKey, &accessPoint)
+	if err != nil {
+		return "", "", err
+	}
+	if accessPoint.Status.AtProvider.AccessPointID != nil {
+		id = *accessPoint.Status.AtProvider.AccessPointID
+	}
+	if accessPoint.Status.AtProvider.LifeCycleState != nil {
+		status = *accessPoint.Status.AtProvider.LifeCycleState
+	}
+	return id, status, nil
 }
 
 func (r *JiraReconciler) getRdsEndpoint(rdsInstance database.RDSInstance, rdsObjKey client.ObjectKey) (endpoint string, err error) {
@@ -77,6 +95,19 @@ func (r *JiraReconciler) getJobSucceededReplicas(job batchv1.Job) (replicas int3
 	return replicas, nil
 }
 
+func (r *JiraReconciler) getJobFailed(job batchv1.Job) (failed bool, err error) {
+	err = r.Get(context.TODO(), client.ObjectKey{Name: job.Name, Namespace: job.Namespace}, &job)
+	if err != nil {
+		return false, err
+	}
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func (r *JiraReconciler) getSvcClusterIp(svc corev1.Service) (ip string, err error) {
 	err = r.Get(context.TODO(), client.ObjectKey{Name: svc.Name, Namespace: svc.Namespace}, &svc)
 	if err != nil {
@@ -104,6 +135,17 @@ func (r *JiraReconciler) getPvcStatus(pvc corev1.PersistentVolumeClaim) (status
 	return status, nil
 }
 
+func (r *JiraReconciler) getVolumeSnapshotReadyToUse(volumeSnapshot snapshot.VolumeSnapshot) (readyToUse bool, err error) {
+	err = r.Get(context.TODO(), client.ObjectKey{Name: volumeSnapshot.Name, Namespace: volumeSnapshot.Namespace}, &volumeSnapshot)
+	if err != nil {
+		return false, err
+	}
+	if volumeSnapshot.Status == nil || volumeSnapshot.Status.ReadyToUse == nil {
+		return false, nil
+	}
+	return *volumeSnapshot.Status.ReadyToUse, nil
+}
+
 func (r *JiraReconciler) getPvByName(name string) (pv corev1.PersistentVolume, err error) {
 	pv = corev1.PersistentVolume{}
 	err = r.Get(context.TODO(), client.ObjectKey{Name: name}, &pv)
@@ -3,6 +3,7 @@ package crossplane
 import (
 	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
 	"github.com/atlassian-labs/jira-operator/k8s"
+	"github.com/atlassian-labs/jira-operator/naming"
 	ec2 "github.com/crossplane-contrib/provider-aws/apis/ec2/v1alpha1"
 	aws "github.com/crossplane-contrib/provider-aws/pkg/clients"
 	v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
@@ -26,29 +27,35 @@ func GetEbsVolume(jira appv1.Jira) (ebsVolume ec2.Volume) {
 		ebsResourceSpec.DeletionPolicy = "Orphan"
 	}
 
+	ebsVolumeParams := ec2.VolumeParameters{
+		Region:           jira.Spec.AWSRegion,
+		AvailabilityZone: aws.String(jira.Spec.AWSRegion + jira.Spec.SharedFS.Ebs.AvailabilityZone),
+		Encrypted:        &encrypted,
+		Size:             &jira.Spec.SharedFS.VolumeSize,
+		TagSpecifications: []*ec2.TagSpecification{
+			{
+				ResourceType: aws.String("volume"),
+				Tags:         k8s.GetTags(jira),
+			},
+		},
+		CustomVolumeParameters: ec2.CustomVolumeParameters{
+			KMSKeyID: &jira.Spec.KMSKeyId,
+		},
+	}
+
+	// without a snapshot an empty volume is created
+	if jira.Spec.SharedFS.Ebs.SnapshotId != "" {
+		ebsVolumeParams.SnapshotID = &jira.Spec.SharedFS.Ebs.SnapshotId
+	}
+
 	ebsVolume = ec2.Volume{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:            jira.Name + "-" + string(jira.UID),
+			Name:            naming.ClusterScoped(jira),
 			OwnerReferences: k8s.GetOwnerReferences(jira),
 		},
 		Spec: ec2.VolumeSpec{
 			ResourceSpec: ebsResourceSpec,
-			ForProvider: ec2.VolumeParameters{
-				Region:           jira.Spec.AWSRegion,
-				AvailabilityZone: aws.String(jira.Spec.AWSRegion + jira.Spec.SharedFS.Ebs.AvailabilityZone),
-				Encrypted:        &encrypted,
-				Size:             &jira.Spec.SharedFS.VolumeSize,
-				SnapshotID:       &jira.Spec.SharedFS.Ebs.SnapshotId,
-				TagSpecifications: []*ec2.TagSpecification{
-					{
-						ResourceType: aws.String("volume"),
-						Tags:         k8s.GetTags(jira),
-					},
-				},
-				CustomVolumeParameters: ec2.CustomVolumeParameters{
-					KMSKeyID: &jira.Spec.KMSKeyId,
-				},
-			},
+			ForProvider:  ebsVolumeParams,
 		},
 	}
 	return ebsVolume
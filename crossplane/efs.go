@@ -3,6 +3,7 @@ package crossplane
 import (
 	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
 	"github.com/atlassian-labs/jira-operator/k8s"
+	"github.com/atlassian-labs/jira-operator/naming"
 	efs "github.com/crossplane-contrib/provider-aws/apis/efs/v1alpha1"
 	aws "github.com/crossplane-contrib/provider-aws/pkg/clients"
 	v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
@@ -10,7 +11,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-func GetMountTargets(jira appv1.Jira, filesystemId string, subnetId string, index string) (mountTarget efs.MountTarget) {
+func GetMountTargets(jira appv1.Jira, filesystemId string, subnetId string, index int) (mountTarget efs.MountTarget) {
 	mountTargetResourceSpec := xpv1.ResourceSpec{
 		ProviderConfigReference: &v1.Reference{
 			Name: jira.Spec.CrossplaneAwsProviderName,
@@ -21,7 +22,7 @@ func GetMountTargets(jira appv1.Jira, filesystemId string, subnetId string, inde
 	}
 	mountTarget = efs.MountTarget{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:            jira.Name + index + "-" + string(jira.UID),
+			Name:            naming.MountTarget(jira, index),
 			OwnerReferences: k8s.GetOwnerReferences(jira),
 		},
 		Spec: efs.MountTargetSpec{
@@ -42,7 +43,7 @@ func GetMountTargets(jira appv1.Jira, filesystemId string, subnetId string, inde
 func GetFileSystem(jira appv1.Jira, namespace string) (sharedFileSystem efs.FileSystem) {
 	efsResourceSpec := xpv1.ResourceSpec{
 		WriteConnectionSecretToReference: &v1.SecretReference{
-			Name:      jira.Name + "-efs-secret",
+			Name:      naming.EfsConnectionSecret(jira),
 			Namespace: namespace,
 		},
 		ProviderConfigReference: &v1.Reference{
@@ -56,7 +57,7 @@ func GetFileSystem(jira appv1.Jira, namespace string) (sharedFileSystem efs.File
 
 	sharedFileSystem = efs.FileSystem{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:            jira.Name + "-" + string(jira.UID),
+			Name:            naming.ClusterScoped(jira),
 			OwnerReferences: k8s.GetOwnerReferences(jira),
 		},
 		Spec: efs.FileSystemSpec{
@@ -65,30 +66,73 @@ func GetFileSystem(jira appv1.Jira, namespace string) (sharedFileSystem efs.File
 				Region:    jira.Spec.AWSRegion,
 				KMSKeyID:  &jira.Spec.KMSKeyId,
 				Encrypted: aws.Bool(true),
-				Tags: []*efs.Tag{
-					{
-						Key:   aws.String("resource_owner"),
-						Value: aws.String("mgibson"),
-					},
-					{
-						Key:   aws.String("service_name"),
-						Value: aws.String("itplat_infrastructure"),
-					},
-					{
-						Key:   aws.String("created_by"),
-						Value: aws.String("jira_operator"),
-					},
-					{
-						Key:   aws.String("business_unit"),
-						Value: aws.String("Workplace Technology"),
-					},
-					{
-						Key:   aws.String("Name"),
-						Value: aws.String(jira.Name + "-" + string(jira.UID)),
+				Tags:      k8s.GetEfsTags(jira),
+			},
+		},
+	}
+	return sharedFileSystem
+}
+
+// Defaults of access point settings, which the CRD only applies when spec.sharedFs.efs.accessPoint is set.
+// Root owned access points cannot be written to by Jira, so a zero uid or gid is defaulted too
+const (
+	accessPointUid         = 2001
+	accessPointGid         = 2001
+	accessPointPermissions = "0750"
+)
+
+func GetAccessPoint(jira appv1.Jira, filesystemId string) (accessPoint efs.AccessPoint) {
+	accessPointResourceSpec := xpv1.ResourceSpec{
+		ProviderConfigReference: &v1.Reference{
+			Name: jira.Spec.CrossplaneAwsProviderName,
+		},
+	}
+	if jira.Spec.RetainOnDelete {
+		accessPointResourceSpec.DeletionPolicy = "Orphan"
+	}
+
+	accessPointSpec := jira.Spec.SharedFS.Efs.AccessPoint
+	if accessPointSpec.Uid == 0 {
+		accessPointSpec.Uid = accessPointUid
+	}
+	if accessPointSpec.Gid == 0 {
+		accessPointSpec.Gid = accessPointGid
+	}
+	if accessPointSpec.Permissions == "" {
+		accessPointSpec.Permissions = accessPointPermissions
+	}
+	rootDirectory := accessPointSpec.RootDirectory
+	if rootDirectory == "" {
+		rootDirectory = "/" + naming.ClusterScoped(jira)
+	}
+
+	accessPoint = efs.AccessPoint{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            naming.ClusterScoped(jira),
+			OwnerReferences: k8s.GetOwnerReferences(jira),
+		},
+		Spec: efs.AccessPointSpec{
+			ResourceSpec: accessPointResourceSpec,
+			ForProvider: efs.AccessPointParameters{
+				Region: jira.Spec.AWSRegion,
+				PosixUser: &efs.PosixUser{
+					Uid: &accessPointSpec.Uid,
+					Gid: &accessPointSpec.Gid,
+				},
+				RootDirectory: &efs.RootDirectory{
+					Path: &rootDirectory,
+					CreationInfo: &efs.CreationInfo{
+						OwnerUid:    &accessPointSpec.Uid,
+						OwnerGid:    &accessPointSpec.Gid,
+						Permissions: &accessPointSpec.Permissions,
 					},
 				},
+				Tags: k8s.GetEfsTags(jira),
+				CustomAccessPointParameters: efs.CustomAccessPointParameters{
+					FileSystemID: &filesystemId,
+				},
 			},
 		},
 	}
-	return sharedFileSystem
+	return accessPoint
 }
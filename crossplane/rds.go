@@ -3,6 +3,7 @@ package crossplane
 import (
 	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
 	"github.com/atlassian-labs/jira-operator/k8s"
+	"github.com/atlassian-labs/jira-operator/naming"
 	database "github.com/crossplane-contrib/provider-aws/apis/database/v1beta1"
 	rds "github.com/crossplane-contrib/provider-aws/apis/rds/v1alpha1"
 	aws "github.com/crossplane-contrib/provider-aws/pkg/clients"
@@ -27,7 +28,7 @@ func GetRdsInstance(jira appv1.Jira, dbSubnetGroup database.DBSubnetGroup, dbPar
 		MasterUsername:       aws.String("postgres"),
 		MasterPasswordSecretRef: &xpv1.SecretKeySelector{
 			SecretReference: xpv1.SecretReference{
-				Name:      "jira-database-secret",
+				Name:      naming.DatabaseSecret(jira),
 				Namespace: namespace,
 			},
 			Key: "password",
@@ -49,7 +50,7 @@ func GetRdsInstance(jira appv1.Jira, dbSubnetGroup database.DBSubnetGroup, dbPar
 
 	rdsResourceSpec := xpv1.ResourceSpec{
 		WriteConnectionSecretToReference: &v1.SecretReference{
-			Name:      jira.Name + "-db-secret",
+			Name:      naming.RdsConnectionSecret(jira),
 			Namespace: namespace,
 		},
 		ProviderConfigReference: &v1.Reference{
@@ -63,7 +64,7 @@ func GetRdsInstance(jira appv1.Jira, dbSubnetGroup database.DBSubnetGroup, dbPar
 
 	rdsInstance = database.RDSInstance{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:            jira.Name + "-" + string(jira.UID),
+			Name:            naming.ClusterScoped(jira),
 			OwnerReferences: k8s.GetOwnerReferences(jira),
 		},
 		Spec: database.RDSInstanceSpec{
@@ -77,7 +78,7 @@ func GetRdsInstance(jira appv1.Jira, dbSubnetGroup database.DBSubnetGroup, dbPar
 func GetDbSubnetGroup(jira appv1.Jira) (dbSubnetGroup database.DBSubnetGroup) {
 	return database.DBSubnetGroup{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:            jira.Name + "-" + string(jira.UID),
+			Name:            naming.ClusterScoped(jira),
 			OwnerReferences: k8s.GetOwnerReferences(jira),
 		},
 		Spec: database.DBSubnetGroupSpec{
@@ -101,7 +102,7 @@ func GetDbParameterGroup(jira appv1.Jira) (dbParameterGroup rds.DBParameterGroup
 	paramaterFamilyVersion := strings.Split(jira.Spec.Database.EngineVersion, ".")[0]
 	return rds.DBParameterGroup{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:            jira.Name + "-" + string(jira.UID),
+			Name:            naming.ClusterScoped(jira),
 			OwnerReferences: k8s.GetOwnerReferences(jira),
 		},
 		Spec: rds.DBParameterGroupSpec{
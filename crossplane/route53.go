@@ -0,0 +1,79 @@
+package crossplane
+
+import (
+	"fmt"
+	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+	"github.com/atlassian-labs/jira-operator/k8s"
+	"github.com/atlassian-labs/jira-operator/naming"
+	route53 "github.com/crossplane-contrib/provider-aws/apis/route53/v1alpha1"
+	aws "github.com/crossplane-contrib/provider-aws/pkg/clients"
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// loadBalancerHostedZoneIds are the hosted zones of application load balancers by region, which alias records point to,
+// see https://docs.aws.amazon.com/general/latest/gr/elb.html
+var loadBalancerHostedZoneIds = map[string]string{
+	"us-east-1":      "Z35SXDOTRQ7X7K",
+	"us-east-2":      "Z3AADJGX6KTTL2",
+	"us-west-1":      "Z368ELLRRE2KJ0",
+	"us-west-2":      "Z1H1FL5HABSF5",
+	"ca-central-1":   "ZQSVJUPU6J1EY",
+	"ap-south-1":     "ZP97RAFLXTNZK",
+	"ap-northeast-1": "Z14GRHDCWA56QT",
+	"ap-northeast-2": "ZWKZPGTI48KDX",
+	"ap-southeast-1": "Z1LMS91P8CMLE5",
+	"ap-southeast-2": "Z1GM3OXH4ZPM65",
+	"eu-central-1":   "Z215JYRZR1TBD5",
+	"eu-west-1":      "Z32O12XQLNTSW2",
+	"eu-west-2":      "ZHURV8PSTC4K8",
+	"sa-east-1":      "Z2P70J7HTTTPLU",
+}
+
+// GetLoadBalancerHostedZoneId returns the hosted zone of load balancers in the region of Jira, unless it is set in spec
+func GetLoadBalancerHostedZoneId(jira appv1.Jira) (hostedZoneId string, err error) {
+	if jira.Spec.DNS.LoadBalancerHostedZoneId != "" {
+		return jira.Spec.DNS.LoadBalancerHostedZoneId, nil
+	}
+	hostedZoneId, ok := loadBalancerHostedZoneIds[jira.Spec.AWSRegion]
+	if !ok {
+		return "", fmt.Errorf("load balancer hosted zone of region %s is not known, set it in spec.dns.loadBalancerHostedZoneId", jira.Spec.AWSRegion)
+	}
+	return hostedZoneId, nil
+}
+
+// GetResourceRecordSet returns an alias record of Jira hostname pointing to the load balancer of its ingress.
+// The record name is the external name of the managed resource, which is cluster scoped like all others
+func GetResourceRecordSet(jira appv1.Jira, loadBalancer string, loadBalancerHostedZoneId string) (recordSet route53.ResourceRecordSet) {
+	recordResourceSpec := xpv1.ResourceSpec{
+		ProviderConfigReference: &xpv1.Reference{
+			Name: jira.Spec.CrossplaneAwsProviderName,
+		},
+	}
+	if jira.Spec.RetainOnDelete {
+		recordResourceSpec.DeletionPolicy = "Orphan"
+	}
+
+	recordSet = route53.ResourceRecordSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            naming.ClusterScoped(jira),
+			OwnerReferences: k8s.GetOwnerReferences(jira),
+		},
+		Spec: route53.ResourceRecordSetSpec{
+			ResourceSpec: recordResourceSpec,
+			ForProvider: route53.ResourceRecordSetParameters{
+				Region: jira.Spec.AWSRegion,
+				Type:   "A",
+				ZoneID: aws.String(jira.Spec.DNS.HostedZoneId),
+				AliasTarget: &route53.AliasTarget{
+					DNSName:              loadBalancer,
+					HostedZoneID:         loadBalancerHostedZoneId,
+					EvaluateTargetHealth: true,
+				},
+			},
+		},
+	}
+	meta.SetExternalName(&recordSet, jira.Spec.Hostname)
+	return recordSet
+}
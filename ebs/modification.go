@@ -0,0 +1,67 @@
+package ebs
+
+import (
+	"errors"
+	"fmt"
+	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"time"
+)
+
+// ErrModificationFailed is returned when AWS failed to modify a volume, as opposed to AWS API errors which can be retried
+var ErrModificationFailed = errors.New("volume modification failed")
+
+// ErrModificationRateExceeded is returned when a volume cannot be modified yet, as AWS allows one modification per volume every 6 hours
+var ErrModificationRateExceeded = errors.New("volume modification rate exceeded")
+
+// modificationCooldown is the time AWS requires between two modifications of an EBS volume
+const modificationCooldown = 6 * time.Hour
+
+// IsVolumeModified returns whether the modification of an EBS volume to a size reached the optimizing state,
+// from which on the volume has its new size and its filesystem can be grown, and an error when it failed.
+// Crossplane reports the volume as ready as soon as the modification is requested, so AWS is asked directly,
+// with the credentials of the operator which need ec2:DescribeVolumesModifications
+func IsVolumeModified(jira appv1.Jira, volumeId string, size int64) (modified bool, err error) {
+	sess, err := session.NewSession(&aws.Config{Region: aws.String(jira.Spec.AWSRegion)})
+	if err != nil {
+		return false, err
+	}
+	output, err := ec2.New(sess).DescribeVolumesModifications(&ec2.DescribeVolumesModificationsInput{VolumeIds: []*string{aws.String(volumeId)}})
+	// a volume which was never modified has no modification until crossplane requests it
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) && awsErr.Code() == "InvalidVolumeModification.NotFound" {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return getModificationState(output.VolumesModifications, volumeId, size, time.Now())
+}
+
+// getModificationState returns whether one of the modifications of a volume modified it to a size. When none of them did,
+// a modification started less than 6 hours ago means that AWS refuses the one crossplane requests, so it is never made
+func getModificationState(modifications []*ec2.VolumeModification, volumeId string, size int64, now time.Time) (modified bool, err error) {
+	var lastStartTime time.Time
+	for _, modification := range modifications {
+		if aws.Int64Value(modification.TargetSize) != size {
+			if startTime := aws.TimeValue(modification.StartTime); startTime.After(lastStartTime) {
+				lastStartTime = startTime
+			}
+			continue
+		}
+		switch aws.StringValue(modification.ModificationState) {
+		case ec2.VolumeModificationStateOptimizing, ec2.VolumeModificationStateCompleted:
+			return true, nil
+		case ec2.VolumeModificationStateFailed:
+			return false, fmt.Errorf("%w: EBS volume %s: %s", ErrModificationFailed, volumeId, aws.StringValue(modification.StatusMessage))
+		}
+		return false, nil
+	}
+	if !lastStartTime.IsZero() && now.Sub(lastStartTime) < modificationCooldown {
+		return false, fmt.Errorf("%w: EBS volume %s was last modified at %s and cannot be modified again before %s",
+			ErrModificationRateExceeded, volumeId, lastStartTime.UTC().Format(time.RFC3339), lastStartTime.Add(modificationCooldown).UTC().Format(time.RFC3339))
+	}
+	return false, nil
+}
@@ -0,0 +1,44 @@
+package ebs
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func TestGetModificationState(t *testing.T) {
+	now := time.Date(2023, 7, 1, 12, 0, 0, 0, time.UTC)
+	modification := func(targetSize int64, state string, startedAgo time.Duration) *ec2.VolumeModification {
+		return &ec2.VolumeModification{TargetSize: aws.Int64(targetSize), ModificationState: aws.String(state), StartTime: aws.Time(now.Add(-startedAgo))}
+	}
+	tests := []struct {
+		name          string
+		modifications []*ec2.VolumeModification
+		wantModified  bool
+		wantErr       error
+	}{
+		{"not requested yet", nil, false, nil},
+		{"modifying", []*ec2.VolumeModification{modification(200, ec2.VolumeModificationStateModifying, time.Minute)}, false, nil},
+		{"optimizing", []*ec2.VolumeModification{modification(200, ec2.VolumeModificationStateOptimizing, time.Minute)}, true, nil},
+		{"completed", []*ec2.VolumeModification{modification(200, ec2.VolumeModificationStateCompleted, time.Hour)}, true, nil},
+		{"failed", []*ec2.VolumeModification{modification(200, ec2.VolumeModificationStateFailed, time.Minute)}, false, ErrModificationFailed},
+		{"previous modification within 6 hours", []*ec2.VolumeModification{modification(150, ec2.VolumeModificationStateCompleted, 2*time.Hour)},
+			false, ErrModificationRateExceeded},
+		{"previous modification more than 6 hours ago", []*ec2.VolumeModification{modification(150, ec2.VolumeModificationStateCompleted, 7*time.Hour)},
+			false, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			modified, err := getModificationState(test.modifications, "vol-1", 200, now)
+			if !errors.Is(err, test.wantErr) || (err != nil) != (test.wantErr != nil) {
+				t.Fatalf("getModificationState() error = %v, want %v", err, test.wantErr)
+			}
+			if modified != test.wantModified {
+				t.Errorf("getModificationState() = %t, want %t", modified, test.wantModified)
+			}
+		})
+	}
+}
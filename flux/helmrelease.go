@@ -0,0 +1,78 @@
+package flux
+
+import (
+	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+	"github.com/atlassian-labs/jira-operator/helm"
+	"github.com/atlassian-labs/jira-operator/k8s"
+	"github.com/atlassian-labs/jira-operator/naming"
+	"github.com/atlassian-labs/jira-operator/product"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Flux types are not imported to keep its dependencies out of the operator, so its resources are built as unstructured
+var (
+	HelmRepositoryGVK = schema.GroupVersionKind{Group: "source.toolkit.fluxcd.io", Version: "v1beta2", Kind: "HelmRepository"}
+	HelmReleaseGVK    = schema.GroupVersionKind{Group: "helm.toolkit.fluxcd.io", Version: "v2beta1", Kind: "HelmRelease"}
+)
+
+// GetHelmRepository returns the Flux source of the product Helm chart
+func GetHelmRepository(jira appv1.Jira) (helmRepository *unstructured.Unstructured) {
+	helmRepository = &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"interval": getInterval(jira),
+			"url":      jira.Spec.ArgoCD.HelmChart.RepoURL,
+		},
+	}}
+	helmRepository.SetGroupVersionKind(HelmRepositoryGVK)
+	setMetadata(jira, helmRepository)
+	return helmRepository
+}
+
+// GetHelmRelease returns the Flux HelmRelease deploying Jira, with the same values Argo CD deploys Jira with.
+// Helm parameters are set in values, as Flux has no equivalent of helm --set
+func GetHelmRelease(jira appv1.Jira) (helmRelease *unstructured.Unstructured, err error) {
+	values, err := helm.GetValues(jira)
+	if err != nil {
+		return nil, err
+	}
+	err = helm.SetParameters(values, helm.GetParameters(jira))
+	if err != nil {
+		return nil, err
+	}
+
+	helmRelease = &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"interval":    getInterval(jira),
+			"releaseName": jira.Name,
+			"chart": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"chart":   product.Get(jira).Chart,
+					"version": jira.Spec.ArgoCD.HelmChart.Version,
+					"sourceRef": map[string]interface{}{
+						"kind": HelmRepositoryGVK.Kind,
+						"name": jira.Name,
+					},
+				},
+			},
+			"values": values,
+		},
+	}}
+	helmRelease.SetGroupVersionKind(HelmReleaseGVK)
+	setMetadata(jira, helmRelease)
+	return helmRelease, nil
+}
+
+func setMetadata(jira appv1.Jira, obj *unstructured.Unstructured) {
+	obj.SetName(jira.Name)
+	obj.SetNamespace(k8s.GetNamespaceName(jira))
+	obj.SetLabels(naming.Labels(jira, naming.ComponentDelivery))
+	obj.SetOwnerReferences(k8s.GetOwnerReferences(jira))
+}
+
+func getInterval(jira appv1.Jira) string {
+	if jira.Spec.Delivery.Flux.Interval == "" {
+		return "5m"
+	}
+	return jira.Spec.Delivery.Flux.Interval
+}
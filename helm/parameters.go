@@ -0,0 +1,121 @@
+package helm
+
+import (
+	"fmt"
+	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+	"github.com/atlassian-labs/jira-operator/product"
+	corev1 "k8s.io/api/core/v1"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Parameter is a Helm value set by name, like helm --set. ForceString keeps a value such as a version from being typed as a number
+type Parameter struct {
+	Name        string
+	Value       string
+	ForceString bool
+}
+
+// GetParameters renders typed Jira spec into Helm parameters, which take precedence over values files and value overrides
+func GetParameters(jira appv1.Jira) (parameters []Parameter) {
+	jiraAppSpec := jira.Spec.Jira
+	chart := product.Get(jira).Chart
+	// a blue/green stack on standby is kept scaled down, as it is replaced by the stack of another Jira
+	if jira.Spec.Maintenance || jira.Status.BlueGreen.Standby {
+		parameters = append(parameters, Parameter{Name: "replicaCount", Value: "0"})
+	} else if jiraAppSpec.Replicas != nil {
+		parameters = append(parameters, Parameter{Name: "replicaCount", Value: strconv.Itoa(int(*jiraAppSpec.Replicas))})
+	}
+	if jiraAppSpec.Version != "" {
+		parameters = append(parameters, Parameter{Name: "image.tag", Value: jiraAppSpec.Version, ForceString: true})
+	}
+	if jiraAppSpec.Jvm.MinHeap != "" {
+		parameters = append(parameters, Parameter{Name: chart + ".resources.jvm.minHeap", Value: jiraAppSpec.Jvm.MinHeap, ForceString: true})
+	}
+	if jiraAppSpec.Jvm.MaxHeap != "" {
+		parameters = append(parameters, Parameter{Name: chart + ".resources.jvm.maxHeap", Value: jiraAppSpec.Jvm.MaxHeap, ForceString: true})
+	}
+	for i, arg := range jiraAppSpec.Jvm.AdditionalArgs {
+		parameters = append(parameters, Parameter{Name: fmt.Sprintf("%s.additionalJvmArgs[%d]", chart, i), Value: arg, ForceString: true})
+	}
+	for _, resourceList := range []struct {
+		name      string
+		resources corev1.ResourceList
+	}{{"requests", jiraAppSpec.Resources.Requests}, {"limits", jiraAppSpec.Resources.Limits}} {
+		resourceNames := make([]string, 0, len(resourceList.resources))
+		for resourceName := range resourceList.resources {
+			resourceNames = append(resourceNames, string(resourceName))
+		}
+		// sorted so that the rendered application does not change between reconciles
+		sort.Strings(resourceNames)
+		for _, resourceName := range resourceNames {
+			quantity := resourceList.resources[corev1.ResourceName(resourceName)]
+			parameters = append(parameters, Parameter{Name: chart + ".resources.container." + resourceList.name + "." + resourceName, Value: quantity.String(), ForceString: true})
+		}
+	}
+	return parameters
+}
+
+// SetParameters sets parameters in values, for delivery backends which only take values. Values are typed as with helm --set,
+// that is integers and booleans are parsed unless ForceString is set
+func SetParameters(values map[string]interface{}, parameters []Parameter) (err error) {
+	for _, parameter := range parameters {
+		var value interface{} = parameter.Value
+		if !parameter.ForceString {
+			if intValue, err := strconv.ParseInt(parameter.Value, 10, 64); err == nil {
+				value = intValue
+			} else if boolValue, err := strconv.ParseBool(parameter.Value); err == nil {
+				value = boolValue
+			}
+		}
+		err = setValue(values, strings.Split(parameter.Name, "."), value)
+		if err != nil {
+			return fmt.Errorf("failed to set helm parameter %s: %w", parameter.Name, err)
+		}
+	}
+	return nil
+}
+
+// setValue sets a value at a path of keys, each of them optionally followed by a list index such as key[0]
+func setValue(values map[string]interface{}, path []string, value interface{}) (err error) {
+	key := path[0]
+	index := -1
+	if open := strings.Index(key, "["); open != -1 && strings.HasSuffix(key, "]") {
+		index, err = strconv.Atoi(key[open+1 : len(key)-1])
+		if err != nil {
+			return err
+		}
+		key = key[:open]
+	}
+
+	if index == -1 {
+		if len(path) == 1 {
+			values[key] = value
+			return nil
+		}
+		child, ok := values[key].(map[string]interface{})
+		if !ok {
+			child = map[string]interface{}{}
+			values[key] = child
+		}
+		return setValue(child, path[1:], value)
+	}
+
+	list, _ := values[key].([]interface{})
+	for len(list) <= index {
+		list = append(list, nil)
+	}
+	if len(path) == 1 {
+		list[index] = value
+	} else {
+		child, ok := list[index].(map[string]interface{})
+		if !ok {
+			child = map[string]interface{}{}
+			list[index] = child
+		}
+		err = setValue(child, path[1:], value)
+	}
+	values[key] = list
+	return err
+}
@@ -0,0 +1,89 @@
+package helm
+
+import (
+	"reflect"
+	"testing"
+
+	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestGetParameters(t *testing.T) {
+	replicas := int32(3)
+	tests := []struct {
+		name string
+		jira appv1.Jira
+		want []Parameter
+	}{
+		{"empty spec", appv1.Jira{}, nil},
+		{"replicas", appv1.Jira{Spec: appv1.JiraSpec{Jira: appv1.JiraAppSpec{Replicas: &replicas}}},
+			[]Parameter{{Name: "replicaCount", Value: "3"}}},
+		{"maintenance scales down", appv1.Jira{Spec: appv1.JiraSpec{Maintenance: true, Jira: appv1.JiraAppSpec{Replicas: &replicas}}},
+			[]Parameter{{Name: "replicaCount", Value: "0"}}},
+		{"standby scales down", appv1.Jira{Spec: appv1.JiraSpec{Jira: appv1.JiraAppSpec{Replicas: &replicas}},
+			Status: appv1.JiraStatus{BlueGreen: appv1.BlueGreenStatus{Standby: true}}},
+			[]Parameter{{Name: "replicaCount", Value: "0"}}},
+		{"version and jvm", appv1.Jira{Spec: appv1.JiraSpec{Jira: appv1.JiraAppSpec{Version: "9.4",
+			Jvm: appv1.JvmSpec{MinHeap: "1g", MaxHeap: "2g", AdditionalArgs: []string{"-Da=b", "-Dc=d"}}}}},
+			[]Parameter{
+				{Name: "image.tag", Value: "9.4", ForceString: true},
+				{Name: "jira.resources.jvm.minHeap", Value: "1g", ForceString: true},
+				{Name: "jira.resources.jvm.maxHeap", Value: "2g", ForceString: true},
+				{Name: "jira.additionalJvmArgs[0]", Value: "-Da=b", ForceString: true},
+				{Name: "jira.additionalJvmArgs[1]", Value: "-Dc=d", ForceString: true},
+			}},
+		{"resources are sorted", appv1.Jira{Spec: appv1.JiraSpec{Product: appv1.ProductConfluence, Jira: appv1.JiraAppSpec{Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("4Gi"), corev1.ResourceCPU: resource.MustParse("2")},
+			Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("8Gi")},
+		}}}},
+			[]Parameter{
+				{Name: "confluence.resources.container.requests.cpu", Value: "2", ForceString: true},
+				{Name: "confluence.resources.container.requests.memory", Value: "4Gi", ForceString: true},
+				{Name: "confluence.resources.container.limits.memory", Value: "8Gi", ForceString: true},
+			}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := GetParameters(test.jira); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("GetParameters() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSetParameters(t *testing.T) {
+	values := map[string]interface{}{
+		"image": map[string]interface{}{"repository": "atlassian/jira-software"},
+		"jira":  map[string]interface{}{"additionalJvmArgs": []interface{}{"-Dexisting=true"}},
+	}
+	err := SetParameters(values, []Parameter{
+		{Name: "replicaCount", Value: "2"},
+		{Name: "image.tag", Value: "9.4", ForceString: true},
+		{Name: "ingress.https", Value: "true"},
+		{Name: "jira.resources.jvm.maxHeap", Value: "2g"},
+		{Name: "jira.additionalJvmArgs[1]", Value: "-Da=b", ForceString: true},
+		{Name: "volumes.additional[0].name", Value: "cache"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"replicaCount": int64(2),
+		"image":        map[string]interface{}{"repository": "atlassian/jira-software", "tag": "9.4"},
+		"ingress":      map[string]interface{}{"https": true},
+		"jira": map[string]interface{}{
+			"additionalJvmArgs": []interface{}{"-Dexisting=true", "-Da=b"},
+			"resources":         map[string]interface{}{"jvm": map[string]interface{}{"maxHeap": "2g"}},
+		},
+		"volumes": map[string]interface{}{"additional": []interface{}{map[string]interface{}{"name": "cache"}}},
+	}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("values = %v, want %v", values, want)
+	}
+
+	err = SetParameters(values, []Parameter{{Name: "jira.additionalJvmArgs[x]", Value: "-Da=b"}})
+	if err == nil {
+		t.Error("SetParameters() with an invalid index succeeded, want error")
+	}
+}
@@ -0,0 +1,254 @@
+package helm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+	"github.com/atlassian-labs/jira-operator/k8s"
+	"github.com/atlassian-labs/jira-operator/product"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/registry"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"strings"
+	"time"
+)
+
+// stalePendingTimeout is how long a release may be pending before it is considered abandoned, for instance by an operator
+// restarted while installing it. Releases are installed without waiting for Jira, so they are pending for seconds
+const stalePendingTimeout = 10 * time.Minute
+
+// GetRelease returns the release of Jira installed by the operator, or nil when it is not installed
+func GetRelease(restConfig *rest.Config, jira appv1.Jira) (rel *release.Release, err error) {
+	actionConfig, err := getActionConfig(context.TODO(), restConfig, jira)
+	if err != nil {
+		return nil, err
+	}
+	rel, err = action.NewGet(actionConfig).Run(jira.Name)
+	if errors.Is(err, driver.ErrReleaseNotFound) {
+		return nil, nil
+	}
+	return rel, err
+}
+
+// IsUpToDate returns whether a release runs the chart version and values of Jira spec
+func IsUpToDate(rel *release.Release, jira appv1.Jira) (upToDate bool, err error) {
+	if rel == nil || rel.Chart == nil || rel.Chart.Metadata.Version != jira.Spec.ArgoCD.HelmChart.Version {
+		return false, nil
+	}
+	values, err := getReleaseValues(jira)
+	if err != nil {
+		return false, err
+	}
+	// values stored in a release are decoded from JSON, so desired values are compared in the same form
+	valuesJson, err := json.Marshal(values)
+	if err != nil {
+		return false, err
+	}
+	desiredValues := map[string]interface{}{}
+	err = json.Unmarshal(valuesJson, &desiredValues)
+	if err != nil {
+		return false, err
+	}
+	releaseValues := rel.Config
+	if releaseValues == nil {
+		releaseValues = map[string]interface{}{}
+	}
+	return reflect.DeepEqual(releaseValues, desiredValues), nil
+}
+
+// InstallOrUpgrade installs the product chart, or upgrades its release when the chart version or values in Jira spec changed.
+// Neither waits for Jira to be ready, and a release which is already being installed or upgraded is left as is, unless
+// it has been pending for longer than stalePendingTimeout, see recoverStaleRelease
+func InstallOrUpgrade(ctx context.Context, restConfig *rest.Config, jira appv1.Jira) (rel *release.Release, err error) {
+	logger := log.FromContext(ctx)
+	rel, err = GetRelease(restConfig, jira)
+	if err != nil {
+		return nil, err
+	}
+	if rel != nil && rel.Info.Status.IsPending() {
+		if time.Since(rel.Info.LastDeployed.Time) < stalePendingTimeout {
+			return rel, nil
+		}
+		rel, err = recoverStaleRelease(ctx, restConfig, jira, rel)
+		if err != nil {
+			return nil, err
+		}
+	}
+	upToDate, err := IsUpToDate(rel, jira)
+	if err != nil {
+		return nil, err
+	}
+	if upToDate && rel.Info.Status == release.StatusDeployed {
+		return rel, nil
+	}
+
+	values, err := getReleaseValues(jira)
+	if err != nil {
+		return nil, err
+	}
+	actionConfig, err := getActionConfig(ctx, restConfig, jira)
+	if err != nil {
+		return nil, err
+	}
+	registryClient, err := registry.NewClient()
+	if err != nil {
+		return nil, err
+	}
+	actionConfig.RegistryClient = registryClient
+
+	if rel == nil {
+		install := action.NewInstall(actionConfig)
+		install.ReleaseName = jira.Name
+		install.Namespace = k8s.GetNamespaceName(jira)
+		install.Version = jira.Spec.ArgoCD.HelmChart.Version
+		productChart, err := loadChart(&install.ChartPathOptions, jira)
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("Installing chart " + productChart.Metadata.Name + " " + productChart.Metadata.Version)
+		return install.RunWithContext(ctx, productChart, values)
+	}
+
+	upgrade := action.NewUpgrade(actionConfig)
+	upgrade.Namespace = k8s.GetNamespaceName(jira)
+	upgrade.Version = jira.Spec.ArgoCD.HelmChart.Version
+	productChart, err := loadChart(&upgrade.ChartPathOptions, jira)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("Upgrading release " + jira.Name + " to chart " + productChart.Metadata.Name + " " + productChart.Metadata.Version)
+	return upgrade.RunWithContext(ctx, jira.Name, productChart, values)
+}
+
+// recoverStaleRelease unblocks a release left pending, which Helm refuses to upgrade. A pending upgrade or rollback is
+// rolled back to the previous revision, and a pending install is uninstalled to be installed again. It returns the
+// release after recovery, nil when it was uninstalled
+func recoverStaleRelease(ctx context.Context, restConfig *rest.Config, jira appv1.Jira, rel *release.Release) (*release.Release, error) {
+	logger := log.FromContext(ctx)
+	actionConfig, err := getActionConfig(ctx, restConfig, jira)
+	if err != nil {
+		return nil, err
+	}
+	if rel.Info.Status == release.StatusPendingInstall {
+		logger.Info("Uninstalling release " + rel.Name + " which has been pending install since " + rel.Info.LastDeployed.String())
+		_, err = action.NewUninstall(actionConfig).Run(rel.Name)
+		if err != nil && !errors.Is(err, driver.ErrReleaseNotFound) {
+			return nil, err
+		}
+		return nil, nil
+	}
+	logger.Info("Rolling back release " + rel.Name + " which has been " + rel.Info.Status.String() + " since " + rel.Info.LastDeployed.String())
+	// revision 0 rolls back to the revision before the pending one
+	err = action.NewRollback(actionConfig).Run(rel.Name)
+	if err != nil {
+		return nil, err
+	}
+	return GetRelease(restConfig, jira)
+}
+
+// Uninstall deletes the release of Jira and all resources of the release, if it is installed
+func Uninstall(ctx context.Context, restConfig *rest.Config, jira appv1.Jira) (err error) {
+	actionConfig, err := getActionConfig(ctx, restConfig, jira)
+	if err != nil {
+		return err
+	}
+	_, err = action.NewUninstall(actionConfig).Run(jira.Name)
+	if errors.Is(err, driver.ErrReleaseNotFound) {
+		return nil
+	}
+	return err
+}
+
+// getReleaseValues returns values with parameters set in them, as helm --set does
+func getReleaseValues(jira appv1.Jira) (values map[string]interface{}, err error) {
+	values, err = GetValues(jira)
+	if err != nil {
+		return nil, err
+	}
+	err = SetParameters(values, GetParameters(jira))
+	return values, err
+}
+
+// loadChart loads the product chart from HelmChart repository, which is a Helm repository URL, an oci:// registry,
+// or a local directory containing the chart directory for offline testing
+func loadChart(pathOptions *action.ChartPathOptions, jira appv1.Jira) (productChart *chart.Chart, err error) {
+	repoURL := jira.Spec.ArgoCD.HelmChart.RepoURL
+	chartName := product.Get(jira).Chart
+	if strings.HasPrefix(repoURL, "file://") || strings.HasPrefix(repoURL, "/") {
+		return loader.Load(filepath.Join(strings.TrimPrefix(repoURL, "file://"), chartName))
+	}
+
+	settings := cli.New()
+	// operator image has no writable home directory
+	settings.RepositoryCache = filepath.Join(os.TempDir(), "helm", "repository")
+	settings.RepositoryConfig = filepath.Join(os.TempDir(), "helm", "repositories.yaml")
+	chartRef := chartName
+	if registry.IsOCI(repoURL) {
+		chartRef = strings.TrimSuffix(repoURL, "/") + "/" + chartName
+	} else {
+		pathOptions.RepoURL = repoURL
+	}
+	chartPath, err := pathOptions.LocateChart(chartRef, settings)
+	if err != nil {
+		return nil, err
+	}
+	return loader.Load(chartPath)
+}
+
+func getActionConfig(ctx context.Context, restConfig *rest.Config, jira appv1.Jira) (actionConfig *action.Configuration, err error) {
+	logger := log.FromContext(ctx)
+	namespace := k8s.GetNamespaceName(jira)
+	actionConfig = new(action.Configuration)
+	err = actionConfig.Init(&restClientGetter{restConfig: restConfig, namespace: namespace}, namespace, "secret", func(format string, v ...interface{}) {
+		logger.V(1).Info(strings.TrimSpace(format), "args", v)
+	})
+	return actionConfig, err
+}
+
+// restClientGetter gives Helm the config of the manager instead of a kubeconfig file
+type restClientGetter struct {
+	restConfig *rest.Config
+	namespace  string
+}
+
+func (g *restClientGetter) ToRESTConfig() (*rest.Config, error) {
+	return rest.CopyConfig(g.restConfig), nil
+}
+
+func (g *restClientGetter) ToDiscoveryClient() (discovery.CachedDiscoveryInterface, error) {
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(g.restConfig)
+	if err != nil {
+		return nil, err
+	}
+	return memory.NewMemCacheClient(discoveryClient), nil
+}
+
+func (g *restClientGetter) ToRESTMapper() (meta.RESTMapper, error) {
+	discoveryClient, err := g.ToDiscoveryClient()
+	if err != nil {
+		return nil, err
+	}
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(discoveryClient)
+	return restmapper.NewShortcutExpander(mapper, discoveryClient), nil
+}
+
+func (g *restClientGetter) ToRawKubeConfigLoader() clientcmd.ClientConfig {
+	overrides := &clientcmd.ConfigOverrides{}
+	overrides.Context.Namespace = g.namespace
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(&clientcmd.ClientConfigLoadingRules{}, overrides)
+}
@@ -0,0 +1,126 @@
+package helm
+
+import (
+	"fmt"
+	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+	"github.com/atlassian-labs/jira-operator/k8s"
+	"github.com/atlassian-labs/jira-operator/naming"
+	"github.com/atlassian-labs/jira-operator/product"
+	"sigs.k8s.io/yaml"
+	"strings"
+)
+
+// GetValues returns Helm values of Jira application. From lowest to highest precedence, Jira is deployed with:
+//   - values of the chart and HelmValuesFiles
+//   - values of the product, see getProductValues
+//   - values the operator computes from Jira spec and the resources it provisioned, see getOperatorValues
+//   - HelmValues.ValueOverrides, deep merged into operator values so that any of them can be overridden
+//   - typed Jira spec rendered as Helm parameters, see GetParameters
+func GetValues(jira appv1.Jira) (values map[string]interface{}, err error) {
+	values = mergeValues(getProductValues(jira), getOperatorValues(jira))
+	overrides := map[string]interface{}{}
+	err = yaml.Unmarshal([]byte(jira.Spec.ArgoCD.HelmValues.ValueOverrides), &overrides)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse helm value overrides: %w", err)
+	}
+	return mergeValues(values, overrides), nil
+}
+
+// getOperatorValues returns values pointing Jira to its ingress, database and shared home.
+// Database URL is known once the RDS instance is available, which is before Argo CD step runs
+func getOperatorValues(jira appv1.Jira) map[string]interface{} {
+	subnets := strings.Join(jira.Spec.Network.SubnetIDs, ",")
+	albTags := strings.Join([]string{
+		"service_name=" + jira.Name,
+		"Name=" + jira.Name,
+		"business_unit=WorkplaceTechnology",
+		"resource_owner=mgibson",
+	}, ",")
+
+	ingressAnnotations := map[string]interface{}{
+		"alb.ingress.kubernetes.io/certificate-arn":         "arn:aws:acm:ap-southeast-2:629205377521:certificate/7d398889-d2ed-42e4-94e7-16fded6498f1",
+		"alb.ingress.kubernetes.io/healthcheck-path":        product.Get(jira).HealthCheckPath,
+		"alb.ingress.kubernetes.io/listen-ports":            "[{\"HTTP\": 80}, {\"HTTPS\": 443}]",
+		"alb.ingress.kubernetes.io/scheme":                  "internal",
+		"alb.ingress.kubernetes.io/ssl-policy":              "ELBSecurityPolicy-FS-1-2-Res-2020-10",
+		"alb.ingress.kubernetes.io/subnets":                 subnets,
+		"alb.ingress.kubernetes.io/tags":                    albTags,
+		"alb.ingress.kubernetes.io/target-group-attributes": "stickiness.enabled=true,stickiness.lb_cookie.duration_seconds=43200",
+		"alb.ingress.kubernetes.io/target-type":             "ip",
+		"external-dns.alpha.kubernetes.io/hostname":         jira.Spec.Hostname,
+		// ingress host is the same on all blue/green stacks, so records are only created from the hostname annotation
+		"external-dns.alpha.kubernetes.io/ingress-hostname-source": "annotation-only",
+	}
+	// with route53 DNS provider the operator manages the record itself
+	if jira.Annotations[appv1.ExternalDNSDisabledAnnotation] == "true" || jira.Spec.DNS.Provider == appv1.DNSProviderRoute53 {
+		delete(ingressAnnotations, "external-dns.alpha.kubernetes.io/hostname")
+	}
+
+	sharedHome := map[string]interface{}{
+		"customVolume": map[string]interface{}{
+			"persistentVolumeClaim": map[string]interface{}{
+				"claimName": naming.SharedHomeClaim(jira),
+			},
+		},
+	}
+	// otherwise the chart fixes permissions of shared home, as ownership of a restored snapshot or an EFS root is unknown
+	if isSharedHomeOwnedByJira(jira) {
+		sharedHome["nfsPermissionFixer"] = map[string]interface{}{
+			"enabled": false,
+		}
+	}
+
+	return map[string]interface{}{
+		"ingress": map[string]interface{}{
+			"host":        jira.Spec.Hostname,
+			"annotations": ingressAnnotations,
+		},
+		"database": map[string]interface{}{
+			"url": k8s.JdbcUrl(jira.Status.RDS.Endpoint, product.Get(jira).Database),
+			"credentials": map[string]interface{}{
+				"secretName": naming.DatabaseSecret(jira),
+			},
+		},
+		"volumes": map[string]interface{}{
+			"sharedHome": sharedHome,
+		},
+	}
+}
+
+// isSharedHomeOwnedByJira returns true when the operator made shared home owned by Jira user, through the NFS init job
+// of an empty EBS volume or the EFS access point
+func isSharedHomeOwnedByJira(jira appv1.Jira) bool {
+	switch jira.Spec.SharedFS.GetType() {
+	case appv1.SharedFSTypeEbs:
+		return jira.Spec.SharedFS.Ebs.SnapshotId == ""
+	case appv1.SharedFSTypeEfs:
+		return jira.Status.SharedFilesystemStatus.EfsAccessPointId != ""
+	}
+	return false
+}
+
+// getProductValues returns values specific to the product deployed, which are overridden by operator values
+func getProductValues(jira appv1.Jira) map[string]interface{} {
+	p := product.Get(jira)
+	values := map[string]interface{}{
+		"database": p.DatabaseValues(jira.Spec.Database.Engine),
+	}
+	if p.Image != "" {
+		values["image"] = map[string]interface{}{"repository": p.Image}
+	}
+	return values
+}
+
+// mergeValues deep merges overrides into values: nested maps are merged key by key, any other override replaces the value
+func mergeValues(values map[string]interface{}, overrides map[string]interface{}) map[string]interface{} {
+	for key, override := range overrides {
+		overrideMap, overrideIsMap := override.(map[string]interface{})
+		valueMap, valueIsMap := values[key].(map[string]interface{})
+		if overrideIsMap && valueIsMap {
+			values[key] = mergeValues(valueMap, overrideMap)
+		} else {
+			values[key] = override
+		}
+	}
+	return values
+}
@@ -0,0 +1,80 @@
+package helm
+
+import (
+	"reflect"
+	"testing"
+
+	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+)
+
+func TestGetValuesPrecedence(t *testing.T) {
+	jira := appv1.Jira{}
+	jira.Name = "jira"
+	jira.Spec.Hostname = "jira.example.com"
+	jira.Spec.Product = appv1.ProductJSM
+	jira.Spec.ArgoCD.HelmValues.ValueOverrides = `
+ingress:
+  annotations:
+    alb.ingress.kubernetes.io/scheme: internet-facing
+database:
+  type: postgres72
+image:
+  tag: "9.4"
+`
+	values, err := GetValues(jira)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ingress := values["ingress"].(map[string]interface{})
+	annotations := ingress["annotations"].(map[string]interface{})
+	if annotations["alb.ingress.kubernetes.io/scheme"] != "internet-facing" {
+		t.Errorf("value overrides do not override operator values: %v", annotations)
+	}
+	if annotations["alb.ingress.kubernetes.io/healthcheck-path"] != "/status" || ingress["host"] != "jira.example.com" {
+		t.Errorf("operator values which are not overridden are lost: %v", ingress)
+	}
+	wantImage := map[string]interface{}{"repository": "atlassian/jira-servicemanagement", "tag": "9.4"}
+	if !reflect.DeepEqual(values["image"], wantImage) {
+		t.Errorf("image = %v, want product values merged with overrides %v", values["image"], wantImage)
+	}
+	database := values["database"].(map[string]interface{})
+	if database["type"] != "postgres72" || database["credentials"] == nil {
+		t.Errorf("database = %v, want overridden type and operator credentials", database)
+	}
+
+	jira.Spec.ArgoCD.HelmValues.ValueOverrides = "ingress: ["
+	if _, err := GetValues(jira); err == nil {
+		t.Error("GetValues() with invalid value overrides succeeded, want error")
+	}
+}
+
+func TestGetValuesNfsPermissionFixer(t *testing.T) {
+	tests := []struct {
+		name        string
+		sharedFS    appv1.SharedFS
+		accessPoint string
+		wantFixer   bool
+	}{
+		{"EFS without access point", appv1.SharedFS{Type: appv1.SharedFSTypeEfs}, "", true},
+		{"EFS with access point", appv1.SharedFS{Type: appv1.SharedFSTypeEfs}, "fsap-1", false},
+		{"empty EBS volume initialized by NFS init job", appv1.SharedFS{Type: appv1.SharedFSTypeEbs}, "", false},
+		{"EBS volume restored from snapshot", appv1.SharedFS{Ebs: appv1.EbsSpec{SnapshotId: "snap-1"}}, "", true},
+		{"FSx volume", appv1.SharedFS{Type: appv1.SharedFSTypeFsx}, "", true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			jira := appv1.Jira{}
+			jira.Spec.SharedFS = test.sharedFS
+			jira.Status.SharedFilesystemStatus.EfsAccessPointId = test.accessPoint
+			values, err := GetValues(jira)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sharedHome := values["volumes"].(map[string]interface{})["sharedHome"].(map[string]interface{})
+			_, disabled := sharedHome["nfsPermissionFixer"]
+			if disabled == test.wantFixer {
+				t.Errorf("nfsPermissionFixer = %v, want chart permission fixer %t", sharedHome["nfsPermissionFixer"], test.wantFixer)
+			}
+		})
+	}
+}
@@ -2,8 +2,10 @@ package k8s
 
 import (
 	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+	"github.com/atlassian-labs/jira-operator/naming"
 	database "github.com/crossplane-contrib/provider-aws/apis/database/v1beta1"
 	ec2 "github.com/crossplane-contrib/provider-aws/apis/ec2/v1alpha1"
+	efs "github.com/crossplane-contrib/provider-aws/apis/efs/v1alpha1"
 	aws "github.com/crossplane-contrib/provider-aws/pkg/clients"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"math/rand"
@@ -35,7 +37,7 @@ func GetTags(jira appv1.Jira) (resourceTags []*ec2.Tag) {
 		},
 		{
 			Key:   aws.String("Name"),
-			Value: aws.String(jira.Name + "-" + string(jira.UID)),
+			Value: aws.String(naming.ClusterScoped(jira)),
 		},
 	}
 	return resourceTags
@@ -76,11 +78,18 @@ func GetDbTags(jira appv1.Jira) []database.Tag {
 		},
 		{
 			Key:   "Name",
-			Value: jira.Name + "-" + string(jira.UID),
+			Value: naming.ClusterScoped(jira),
 		},
 	}
 }
 
+func GetEfsTags(jira appv1.Jira) (resourceTags []*efs.Tag) {
+	for _, tag := range GetTags(jira) {
+		resourceTags = append(resourceTags, &efs.Tag{Key: tag.Key, Value: tag.Value})
+	}
+	return resourceTags
+}
+
 func GeneratePasswd(stringLength int) (passwd string) {
 	rand.Seed(time.Now().UnixNano())
 	chars := []rune("abcdefghijklmnopqrstuvwxyz" + "%()$#" + "ABCDEFGHIJKLMNOPQRSTUVWXYZ" + "0123456789")
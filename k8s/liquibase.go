@@ -3,6 +3,7 @@ package k8s
 import (
 	"fmt"
 	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+	"github.com/atlassian-labs/jira-operator/naming"
 	aws "github.com/crossplane-contrib/provider-aws/pkg/clients"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -14,20 +15,22 @@ func GetLiquibaseSecret(jira appv1.Jira, namespace, rdsHostname string, masterPa
 	liquibaseSecretData := map[string][]byte{
 		"password":                masterPassword,
 		"username":                []byte("postgres"),
-		"url":                     []byte("jdbc:postgresql://" + rdsHostname + "/postgres"),
+		"url":                     []byte(JdbcUrl(rdsHostname, "postgres")),
 		"hostname":                []byte(rdsHostname),
 		"changeLogFile":           []byte("changelog.yml"),
 		"classpath":               []byte("changelog"),
-		"parameter.appUsername":   []byte("jira"),
-		"parameter.appRoUsername": []byte("jira-ro"),
 		"parameter.appPassword":   appUserJDBCPassword,
 		"parameter.appRoPassword": []byte(GeneratePasswd(26)),
 	}
+	for key, value := range GetProductDatabaseParameters(jira) {
+		liquibaseSecretData[key] = value
+	}
 
 	liquibaseSecret = corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:            jira.Name + "-liquibase-properties-secret",
+			Name:            naming.LiquibaseSecret(jira),
 			Namespace:       namespace,
+			Labels:          naming.Labels(jira, naming.ComponentLiquibase),
 			OwnerReferences: GetOwnerReferences(jira),
 		},
 		Data: liquibaseSecretData,
@@ -44,8 +47,9 @@ func GetLiquibaseConfigMap(jira appv1.Jira, namespace string) (liquibaseConfigMa
 
 	liquibaseConfigMap = corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:            jira.Name + "-liquibase-changelog",
+			Name:            naming.LiquibaseChangelog(jira),
 			Namespace:       namespace,
+			Labels:          naming.Labels(jira, naming.ComponentLiquibase),
 			OwnerReferences: GetOwnerReferences(jira),
 		},
 		Data: map[string]string{
@@ -58,8 +62,9 @@ func GetLiquibaseConfigMap(jira appv1.Jira, namespace string) (liquibaseConfigMa
 func GetServiceAccount(jira appv1.Jira, namespace string) (serviceAccout corev1.ServiceAccount) {
 	serviceAccout = corev1.ServiceAccount{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "rds-reset-password-sa",
+			Name:      naming.ResetRdsPasswordServiceAccount(jira),
 			Namespace: namespace,
+			Labels:    naming.Labels(jira, naming.ComponentResetRdsPassword),
 			Annotations: map[string]string{
 				"eks.amazonaws.com/role-arn": jira.Spec.RdsRoleArn,
 			},
@@ -72,16 +77,15 @@ func GetChangeRootPasswordJob(jira appv1.Jira, namespace string, dbInstanceIdent
 	awsCliCommand := fmt.Sprintf("aws rds modify-db-instance --db-instance-identifier=%s --master-user-password $PGPASSWORD --region %s --apply-immediately", dbInstanceIdentifier, jira.Spec.AWSRegion)
 	changeRootPasswordJob = batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      jira.Name + "-reset-rds-credentials",
+			Name:      naming.ResetRdsPasswordJob(jira),
 			Namespace: namespace,
+			Labels:    naming.Labels(jira, naming.ComponentResetRdsPassword),
 		},
 		Spec: batchv1.JobSpec{
 			BackoffLimit: aws.Int32(20),
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels: map[string]string{
-						"owner": jira.Name,
-					},
+					Labels: naming.Labels(jira, naming.ComponentResetRdsPassword),
 				},
 				Spec: corev1.PodSpec{
 					ServiceAccountName: GetServiceAccount(jira, namespace).Name,
@@ -96,7 +100,7 @@ func GetChangeRootPasswordJob(jira appv1.Jira, namespace string, dbInstanceIdent
 								ValueFrom: &corev1.EnvVarSource{
 									SecretKeyRef: &corev1.SecretKeySelector{
 										LocalObjectReference: corev1.LocalObjectReference{
-											Name: "jira-database-secret",
+											Name: naming.DatabaseSecret(jira),
 										},
 										Key: "password",
 									},
@@ -115,15 +119,14 @@ func GetChangeRootPasswordJob(jira appv1.Jira, namespace string, dbInstanceIdent
 func GetLiquibaseJob(jira appv1.Jira, namespace string) (liquibaseJob batchv1.Job) {
 	liquibaseJob = batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      jira.Name + "-liquibase-changeset",
+			Name:      naming.LiquibaseJob(jira),
 			Namespace: namespace,
+			Labels:    naming.Labels(jira, naming.ComponentLiquibase),
 		},
 		Spec: batchv1.JobSpec{
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels: map[string]string{
-						"owner": jira.Name,
-					},
+					Labels: naming.Labels(jira, naming.ComponentLiquibase),
 				},
 				Spec: corev1.PodSpec{
 					Volumes: []corev1.Volume{
@@ -131,7 +134,7 @@ func GetLiquibaseJob(jira appv1.Jira, namespace string) (liquibaseJob batchv1.Jo
 							Name: "jira-database-secret",
 							VolumeSource: corev1.VolumeSource{
 								Secret: &corev1.SecretVolumeSource{
-									SecretName: "jira-database-secret",
+									SecretName: naming.DatabaseSecret(jira),
 								},
 							},
 						},
@@ -140,7 +143,7 @@ func GetLiquibaseJob(jira appv1.Jira, namespace string) (liquibaseJob batchv1.Jo
 							VolumeSource: corev1.VolumeSource{
 								ConfigMap: &corev1.ConfigMapVolumeSource{
 									LocalObjectReference: corev1.LocalObjectReference{
-										Name: jira.Name + "-liquibase-changelog",
+										Name: naming.LiquibaseChangelog(jira),
 									},
 								},
 							},
@@ -158,7 +161,7 @@ func GetLiquibaseJob(jira appv1.Jira, namespace string) (liquibaseJob batchv1.Jo
 									ValueFrom: &corev1.EnvVarSource{
 										SecretKeyRef: &corev1.SecretKeySelector{
 											LocalObjectReference: corev1.LocalObjectReference{
-												Name: "jira-database-secret",
+												Name: naming.DatabaseSecret(jira),
 											},
 											Key: "password",
 										},
@@ -169,7 +172,7 @@ func GetLiquibaseJob(jira appv1.Jira, namespace string) (liquibaseJob batchv1.Jo
 									ValueFrom: &corev1.EnvVarSource{
 										SecretKeyRef: &corev1.SecretKeySelector{
 											LocalObjectReference: corev1.LocalObjectReference{
-												Name: "jira-database-secret",
+												Name: naming.DatabaseSecret(jira),
 											},
 											Key: "url",
 										},
@@ -2,18 +2,63 @@ package k8s
 
 import (
 	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+	"github.com/atlassian-labs/jira-operator/naming"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// GetNamespaceName returns the namespace Jira and its dependencies are deployed to
+func GetNamespaceName(jira appv1.Jira) string {
+	if jira.Spec.TargetNamespace.Name != "" {
+		return jira.Spec.TargetNamespace.Name
+	}
+	return jira.Name
+}
+
 func GetNamespace(jira appv1.Jira) (namespace corev1.Namespace) {
+	labels := map[string]string{"owned_by": jira.Name}
+	for key, value := range jira.Spec.TargetNamespace.Labels {
+		labels[key] = value
+	}
+	annotations := map[string]string{"owned_by": jira.Name}
+	for key, value := range jira.Spec.TargetNamespace.Annotations {
+		annotations[key] = value
+	}
+
 	namespace = corev1.Namespace{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:            jira.Name,
-			Labels:          map[string]string{"owned_by": jira.Name},
-			Annotations:     map[string]string{"owned_by": jira.Name},
-			OwnerReferences: GetOwnerReferences(jira),
+			Name:        GetNamespaceName(jira),
+			Labels:      labels,
+			Annotations: annotations,
 		},
 	}
+	// only a namespace created by the operator is deleted together with Jira
+	if jira.Spec.TargetNamespace.Mode == "" || jira.Spec.TargetNamespace.Mode == appv1.NamespaceModeCreate {
+		namespace.OwnerReferences = GetOwnerReferences(jira)
+	}
 	return namespace
 }
+
+func GetResourceQuota(jira appv1.Jira, namespace string) (resourceQuota corev1.ResourceQuota) {
+	resourceQuota = corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            naming.ResourceQuota(jira),
+			Namespace:       namespace,
+			OwnerReferences: GetOwnerReferences(jira),
+		},
+		Spec: *jira.Spec.TargetNamespace.ResourceQuota,
+	}
+	return resourceQuota
+}
+
+func GetLimitRange(jira appv1.Jira, namespace string) (limitRange corev1.LimitRange) {
+	limitRange = corev1.LimitRange{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            naming.LimitRange(jira),
+			Namespace:       namespace,
+			OwnerReferences: GetOwnerReferences(jira),
+		},
+		Spec: *jira.Spec.TargetNamespace.LimitRange,
+	}
+	return limitRange
+}
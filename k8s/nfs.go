@@ -1,18 +1,38 @@
 package k8s
 
 import (
+	"fmt"
 	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+	"github.com/atlassian-labs/jira-operator/naming"
+	"github.com/atlassian-labs/jira-operator/product"
 	"github.com/aws/aws-sdk-go/aws"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// Defaults of nfs server settings, for Jiras created before the CRD defaulted them
+const (
+	nfsServerImage                         = "atlassian/nfs-server-test:2.1"
+	nfsServerTerminationGracePeriodSeconds = 30
+)
+
+func getNfsServerImage(jira appv1.Jira) string {
+	if jira.Spec.SharedFS.NfsServer.Image == "" {
+		return nfsServerImage
+	}
+	return jira.Spec.SharedFS.NfsServer.Image
+}
+
 func GetNfSServerService(jira appv1.Jira, namespace string) (svc corev1.Service) {
 	svc = corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:            jira.Name + "-nfs-server",
+			Name:            naming.NfsServer(jira),
 			Namespace:       namespace,
+			Labels:          naming.Labels(jira, naming.ComponentNfsServer),
 			OwnerReferences: GetOwnerReferences(jira),
 		},
 		Spec: corev1.ServiceSpec{
@@ -23,10 +43,8 @@ func GetNfSServerService(jira appv1.Jira, namespace string) (svc corev1.Service)
 					Port:     2049,
 				},
 			},
-			Selector: map[string]string{
-				"app": "nfs-server",
-			},
-			Type: corev1.ServiceTypeClusterIP,
+			Selector: naming.NfsServerSelectorLabels(jira),
+			Type:     corev1.ServiceTypeClusterIP,
 		},
 	}
 
@@ -34,10 +52,63 @@ func GetNfSServerService(jira appv1.Jira, namespace string) (svc corev1.Service)
 }
 
 func GetNfsServerStatefulSet(jira appv1.Jira, namespace string) (sts appsv1.StatefulSet) {
+	nfsServerSpec := jira.Spec.SharedFS.NfsServer
+	nfsServerSpec.Image = getNfsServerImage(jira)
+	if nfsServerSpec.TerminationGracePeriodSeconds == nil {
+		nfsServerSpec.TerminationGracePeriodSeconds = aws.Int64(nfsServerTerminationGracePeriodSeconds)
+	}
+	affinity := &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{
+				{
+					MatchExpressions: []corev1.NodeSelectorRequirement{
+						{
+							Key:      "topology.kubernetes.io/zone",
+							Operator: corev1.NodeSelectorOpIn,
+							Values:   []string{jira.Spec.AWSRegion + jira.Spec.SharedFS.Ebs.AvailabilityZone},
+						},
+						{
+							Key:      "topology.kubernetes.io/region",
+							Operator: corev1.NodeSelectorOpIn,
+							Values:   []string{jira.Spec.AWSRegion},
+						},
+					},
+				},
+			},
+			},
+		},
+	}
+	// Jira pods are labelled by the data center helm chart, release name is the Jira name
+	if nfsServerSpec.JiraPodAntiAffinity {
+		affinity.PodAntiAffinity = &corev1.PodAntiAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{
+				{
+					Weight: 100,
+					PodAffinityTerm: corev1.PodAffinityTerm{
+						LabelSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{
+								"app.kubernetes.io/name":     product.Get(jira).Chart,
+								"app.kubernetes.io/instance": jira.Name,
+							},
+						},
+						TopologyKey: "kubernetes.io/hostname",
+					},
+				},
+			},
+		}
+	}
+
+	// pods keep the selector labels of Jiras with legacy names next to the standard ones
+	podLabels := naming.Labels(jira, naming.ComponentNfsServer)
+	for key, value := range naming.NfsServerSelectorLabels(jira) {
+		podLabels[key] = value
+	}
+
 	sts = appsv1.StatefulSet{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:            jira.Name + "-nfs-server",
+			Name:            naming.NfsServer(jira),
 			Namespace:       namespace,
+			Labels:          naming.Labels(jira, naming.ComponentNfsServer),
 			OwnerReferences: GetOwnerReferences(jira),
 		},
 		Spec: appsv1.StatefulSetSpec{
@@ -46,53 +117,31 @@ func GetNfsServerStatefulSet(jira appv1.Jira, namespace string) (sts appsv1.Stat
 			},
 			Replicas: aws.Int32(1),
 			Selector: &metav1.LabelSelector{
-				MatchLabels: map[string]string{
-					"app": "nfs-server",
-				},
+				MatchLabels: naming.NfsServerSelectorLabels(jira),
 			},
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels: map[string]string{
-						"app": "nfs-server",
-					},
+					Labels: podLabels,
 				},
 				Spec: corev1.PodSpec{
-					TerminationGracePeriodSeconds: aws.Int64(0),
-					Affinity: &corev1.Affinity{
-						NodeAffinity: &corev1.NodeAffinity{
-							RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{
-								{
-									MatchExpressions: []corev1.NodeSelectorRequirement{
-										{
-											Key:      "topology.kubernetes.io/zone",
-											Operator: corev1.NodeSelectorOpIn,
-											Values:   []string{jira.Spec.AWSRegion + jira.Spec.SharedFS.Ebs.AvailabilityZone},
-										},
-										{
-											Key:      "topology.kubernetes.io/region",
-											Operator: corev1.NodeSelectorOpIn,
-											Values:   []string{jira.Spec.AWSRegion},
-										},
-									},
-								},
-							},
-							},
-						},
-					},
+					TerminationGracePeriodSeconds: nfsServerSpec.TerminationGracePeriodSeconds,
+					PriorityClassName:             nfsServerSpec.PriorityClassName,
+					Affinity:                      affinity,
 					Volumes: []corev1.Volume{
 						{
 							Name: "data",
 							VolumeSource: corev1.VolumeSource{
 								PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
-									ClaimName: jira.Name + "-nfs-server",
+									ClaimName: naming.NfsServer(jira),
 								},
 							},
 						},
 					},
 					Containers: []corev1.Container{
 						{
-							Name:  "nfs-server",
-							Image: "atlassian/nfs-server-test:2.1",
+							Name:      "nfs-server",
+							Image:     nfsServerSpec.Image,
+							Resources: nfsServerSpec.Resources,
 							SecurityContext: &corev1.SecurityContext{
 								Capabilities: &corev1.Capabilities{
 									Add: []corev1.Capability{"DAC_READ_SEARCH", "SYS_RESOURCE"},
@@ -121,12 +170,158 @@ func GetNfsServerStatefulSet(jira appv1.Jira, namespace string) (sts appsv1.Stat
 								PeriodSeconds:       1,
 								FailureThreshold:    30,
 							},
+							LivenessProbe: &corev1.Probe{
+								ProbeHandler: corev1.ProbeHandler{
+									TCPSocket: &corev1.TCPSocketAction{
+										Port: intstr.FromInt(2049),
+									},
+								},
+								InitialDelaySeconds: 30,
+								PeriodSeconds:       10,
+								FailureThreshold:    6,
+							},
+							// stop exporting before shutdown, so that clients get a clean error rather than hang on a half closed server
+							Lifecycle: &corev1.Lifecycle{
+								PreStop: &corev1.LifecycleHandler{
+									Exec: &corev1.ExecAction{
+										Command: []string{"/bin/sh", "-c", "exportfs -ua; sleep 5"},
+									},
+								},
+							},
 						},
 					},
 				},
 			},
-			ServiceName: "nfs-server",
+			ServiceName: naming.NfsServerService(jira),
 		},
 	}
 	return sts
 }
+
+// GetNfsServerPodDisruptionBudget returns a PDB keeping the single nfs server replica from being evicted
+func GetNfsServerPodDisruptionBudget(jira appv1.Jira, namespace string) (pdb policyv1.PodDisruptionBudget) {
+	minAvailable := intstr.FromInt(1)
+	pdb = policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            naming.NfsServer(jira),
+			Namespace:       namespace,
+			Labels:          naming.Labels(jira, naming.ComponentNfsServer),
+			OwnerReferences: GetOwnerReferences(jira),
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MinAvailable: &minAvailable,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: naming.NfsServerSelectorLabels(jira),
+			},
+		},
+	}
+	return pdb
+}
+
+// GetNfsInitJob returns a job which prepares an empty EBS volume for Jira before nfs server exports it.
+// The volume is formatted by kubelet on the first mount, so the job only needs to set ownership
+func GetNfsInitJob(jira appv1.Jira, namespace string) (nfsInitJob batchv1.Job) {
+	initCommand := fmt.Sprintf("chown %d:%d /srv/nfs && chmod 0770 /srv/nfs", jira.Spec.SharedFS.Ebs.OwnerUid, jira.Spec.SharedFS.Ebs.OwnerGid)
+	nfsInitJob = batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            naming.NfsInitJob(jira),
+			Namespace:       namespace,
+			Labels:          naming.Labels(jira, naming.ComponentNfsInit),
+			OwnerReferences: GetOwnerReferences(jira),
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: aws.Int32(5),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: naming.Labels(jira, naming.ComponentNfsInit),
+				},
+				Spec: corev1.PodSpec{
+					Volumes: []corev1.Volume{
+						{
+							Name: "data",
+							VolumeSource: corev1.VolumeSource{
+								PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+									ClaimName: naming.NfsServer(jira),
+								},
+							},
+						},
+					},
+					Containers: []corev1.Container{
+						{
+							Name:    "nfs-init",
+							Image:   "busybox:1.36",
+							Command: []string{"/bin/sh", "-c"},
+							Args:    []string{initCommand},
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									Name:      "data",
+									MountPath: "/srv/nfs",
+								},
+							},
+						},
+					},
+					RestartPolicy: corev1.RestartPolicyNever,
+				},
+			},
+		},
+	}
+	return nfsInitJob
+}
+
+// GetNfsResizeJob returns a job which grows the filesystem exported by nfs server to the size of the underlying volume.
+// Growing a mounted filesystem needs CAP_SYS_ADMIN, which nfs server does not get, so the job runs privileged
+// on the node of nfs server, where the volume is already mounted
+func GetNfsResizeJob(jira appv1.Jira, namespace string, nodeName string, size int64) (nfsResizeJob batchv1.Job) {
+	resizeCommand := "resize2fs $(awk '$2 == \"/srv/nfs\" {print $1}' /proc/mounts)"
+	if jira.Spec.SharedFS.Ebs.EbsFsType == "xfs" {
+		resizeCommand = "xfs_growfs /srv/nfs"
+	}
+	nfsResizeJob = batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            naming.NfsResizeJob(jira, size),
+			Namespace:       namespace,
+			Labels:          naming.Labels(jira, naming.ComponentNfsResize),
+			OwnerReferences: GetOwnerReferences(jira),
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: aws.Int32(5),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: naming.Labels(jira, naming.ComponentNfsResize),
+				},
+				Spec: corev1.PodSpec{
+					NodeName: nodeName,
+					Volumes: []corev1.Volume{
+						{
+							Name: "data",
+							VolumeSource: corev1.VolumeSource{
+								PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+									ClaimName: naming.NfsServer(jira),
+								},
+							},
+						},
+					},
+					Containers: []corev1.Container{
+						{
+							Name:    "nfs-resize",
+							Image:   getNfsServerImage(jira),
+							Command: []string{"/bin/sh", "-c"},
+							Args:    []string{resizeCommand},
+							SecurityContext: &corev1.SecurityContext{
+								Privileged: aws.Bool(true),
+							},
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									Name:      "data",
+									MountPath: "/srv/nfs",
+								},
+							},
+						},
+					},
+					RestartPolicy: corev1.RestartPolicyNever,
+				},
+			},
+		},
+	}
+	return nfsResizeJob
+}
@@ -2,28 +2,48 @@ package k8s
 
 import (
 	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+	"github.com/atlassian-labs/jira-operator/naming"
+	"github.com/atlassian-labs/jira-operator/product"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// JdbcUrl returns the JDBC URL of a database of the RDS instance
+func JdbcUrl(rdsHostname string, database string) string {
+	return "jdbc:postgresql://" + rdsHostname + "/" + database
+}
+
+// GetProductDatabaseParameters returns liquibase changelog parameters naming the database and users of the product
+func GetProductDatabaseParameters(jira appv1.Jira) map[string][]byte {
+	p := product.Get(jira)
+	return map[string][]byte{
+		"parameter.appDatabase":   []byte(p.Database),
+		"parameter.appUsername":   []byte(p.DatabaseUser),
+		"parameter.appRoUsername": []byte(p.DatabaseReadOnlyUser),
+	}
+}
+
 func GetRdsSecret(jira appv1.Jira, rdsHostname string, namespace string) (rdsMasterPasswordSecret corev1.Secret) {
+	p := product.Get(jira)
 	secretData := map[string][]byte{
 		"password":                []byte(GeneratePasswd(26)),
 		"username":                []byte("postgres"),
-		"url":                     []byte("jdbc:postgresql://" + rdsHostname + "/postgres"),
-		"jdbcUrl":                 []byte("jdbc:postgresql://" + rdsHostname + "/jira"),
+		"url":                     []byte(JdbcUrl(rdsHostname, "postgres")),
+		"jdbcUrl":                 []byte(JdbcUrl(rdsHostname, p.Database)),
 		"hostname":                []byte(rdsHostname),
 		"changeLogFile":           []byte("changelog.yml"),
 		"classpath":               []byte("changelog"),
-		"parameter.appUsername":   []byte("jira"),
-		"parameter.appRoUsername": []byte("jira-ro"),
 		"parameter.appPassword":   []byte(GeneratePasswd(26)),
 		"parameter.appRoPassword": []byte(GeneratePasswd(26)),
 	}
+	for key, value := range GetProductDatabaseParameters(jira) {
+		secretData[key] = value
+	}
 	rdsMasterPasswordSecret = corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:            "jira-database-secret",
+			Name:            naming.DatabaseSecret(jira),
 			Namespace:       namespace,
+			Labels:          naming.Labels(jira, naming.ComponentDatabase),
 			OwnerReferences: GetOwnerReferences(jira),
 		},
 		Data: secretData,
@@ -32,15 +52,17 @@ func GetRdsSecret(jira appv1.Jira, rdsHostname string, namespace string) (rdsMas
 }
 
 func GetJiraUserRdsSecret(jira appv1.Jira, namespace, rdsHostname string) (databaseSecret corev1.Secret) {
+	p := product.Get(jira)
 	jiraRdsSecretData := map[string][]byte{
 		"password": []byte(GeneratePasswd(26)),
-		"username": []byte("jira"),
-		"jdbcUrl":  []byte("jdbc:postgresql://" + rdsHostname + "/jira"),
+		"username": []byte(p.DatabaseUser),
+		"jdbcUrl":  []byte(JdbcUrl(rdsHostname, p.Database)),
 	}
 	databaseSecret = corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:            "jira-database-secret",
+			Name:            naming.DatabaseSecret(jira),
 			Namespace:       namespace,
+			Labels:          naming.Labels(jira, naming.ComponentDatabase),
 			OwnerReferences: GetOwnerReferences(jira),
 		},
 		Data: jiraRdsSecretData,
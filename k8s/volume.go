@@ -2,6 +2,7 @@ package k8s
 
 import (
 	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+	"github.com/atlassian-labs/jira-operator/naming"
 	aws "github.com/crossplane-contrib/provider-aws/pkg/clients"
 	snapshot "github.com/kubernetes-csi/external-snapshotter/client/v6/apis/volumesnapshot/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -12,7 +13,8 @@ import (
 func GetNfsPersistentVolume(jira appv1.Jira, nfsClusterIp string, size string, namespace string) (pv corev1.PersistentVolume) {
 	pv = corev1.PersistentVolume{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:            "jira-shared-home-pv" + "-" + string(jira.UID),
+			Name:            naming.SharedHomePersistentVolume(jira),
+			Labels:          naming.Labels(jira, naming.ComponentSharedHome),
 			OwnerReferences: GetOwnerReferences(jira),
 		},
 		Spec: corev1.PersistentVolumeSpec{
@@ -29,7 +31,7 @@ func GetNfsPersistentVolume(jira appv1.Jira, nfsClusterIp string, size string, n
 			ClaimRef: &corev1.ObjectReference{
 				Kind:      "PersistentVolumeClaim",
 				Namespace: namespace,
-				Name:      "jira-shared-home",
+				Name:      naming.SharedHomeClaim(jira),
 			},
 			PersistentVolumeReclaimPolicy: corev1.PersistentVolumeReclaimRetain,
 			StorageClassName:              jira.Spec.SharedFS.Ebs.EbsStorageClassName,
@@ -38,10 +40,19 @@ func GetNfsPersistentVolume(jira appv1.Jira, nfsClusterIp string, size string, n
 	return pv
 }
 
-func GetEfsPersistentVolume(jira appv1.Jira, efsId string, namespace string) (pv corev1.PersistentVolume) {
+// GetEfsVolumeHandle returns the CSI volume handle for the filesystem, mounted through the access point if there is one
+func GetEfsVolumeHandle(efsId string, accessPointId string) string {
+	if accessPointId == "" {
+		return efsId
+	}
+	return efsId + "::" + accessPointId
+}
+
+func GetEfsPersistentVolume(jira appv1.Jira, efsId string, accessPointId string, namespace string) (pv corev1.PersistentVolume) {
 	pv = corev1.PersistentVolume{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:            "jira-shared-home-pv" + "-" + string(jira.UID),
+			Name:            naming.SharedHomePersistentVolume(jira),
+			Labels:          naming.Labels(jira, naming.ComponentSharedHome),
 			OwnerReferences: GetOwnerReferences(jira),
 		},
 		Spec: corev1.PersistentVolumeSpec{
@@ -51,7 +62,7 @@ func GetEfsPersistentVolume(jira appv1.Jira, efsId string, namespace string) (pv
 			PersistentVolumeSource: corev1.PersistentVolumeSource{
 				CSI: &corev1.CSIPersistentVolumeSource{
 					Driver:       jira.Spec.SharedFS.Efs.EfsCsiDriverName,
-					VolumeHandle: efsId,
+					VolumeHandle: GetEfsVolumeHandle(efsId, accessPointId),
 					ReadOnly:     false,
 				},
 			},
@@ -59,7 +70,7 @@ func GetEfsPersistentVolume(jira appv1.Jira, efsId string, namespace string) (pv
 			ClaimRef: &corev1.ObjectReference{
 				Kind:      "PersistentVolumeClaim",
 				Namespace: namespace,
-				Name:      "jira-shared-home",
+				Name:      naming.SharedHomeClaim(jira),
 			},
 			PersistentVolumeReclaimPolicy: corev1.PersistentVolumeReclaimRetain,
 			StorageClassName:              jira.Spec.SharedFS.Efs.EfsStorageClassName,
@@ -72,6 +83,7 @@ func GetEbsPersistentVolume(jira appv1.Jira, ebsVolumeId string, name string, si
 	pv = corev1.PersistentVolume{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:            name + "-" + string(jira.UID),
+			Labels:          naming.Labels(jira, naming.ComponentNfsServer),
 			OwnerReferences: GetOwnerReferences(jira),
 		},
 		Spec: corev1.PersistentVolumeSpec{
@@ -137,7 +149,7 @@ func GetPersistentVolumeClaim(jira appv1.Jira, name string, namespace string, vo
 func GetFsxVolumeSnapshotContent(jira appv1.Jira, namespace string) (snapshotContent snapshot.VolumeSnapshotContent) {
 	snapshotContent = snapshot.VolumeSnapshotContent{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:            jira.Name + "-" + string(jira.UID),
+			Name:            naming.ClusterScoped(jira),
 			Namespace:       namespace,
 			OwnerReferences: GetOwnerReferences(jira),
 		},
@@ -145,7 +157,7 @@ func GetFsxVolumeSnapshotContent(jira appv1.Jira, namespace string) (snapshotCon
 			VolumeSnapshotRef: corev1.ObjectReference{
 				Kind:      "VolumeSnapshot",
 				Namespace: namespace,
-				Name:      jira.Name + "-" + string(jira.UID),
+				Name:      naming.ClusterScoped(jira),
 			},
 			Driver:                  jira.Spec.SharedFS.Fsx.FsxCsiDriverName,
 			VolumeSnapshotClassName: &jira.Spec.SharedFS.Fsx.FsxVolumeSnapshotClassName,
@@ -161,13 +173,13 @@ func GetFsxVolumeSnapshotContent(jira appv1.Jira, namespace string) (snapshotCon
 func GetFsxVolumeSnapshot(jira appv1.Jira, namespace string) (volumeSnapshot snapshot.VolumeSnapshot) {
 	volumeSnapshot = snapshot.VolumeSnapshot{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:            jira.Name + "-" + string(jira.UID),
+			Name:            naming.ClusterScoped(jira),
 			Namespace:       namespace,
 			OwnerReferences: GetOwnerReferences(jira),
 		},
 		Spec: snapshot.VolumeSnapshotSpec{
 			Source: snapshot.VolumeSnapshotSource{
-				VolumeSnapshotContentName: aws.String(jira.Name + "-" + string(jira.UID)),
+				VolumeSnapshotContentName: aws.String(naming.ClusterScoped(jira)),
 			},
 			VolumeSnapshotClassName: &jira.Spec.SharedFS.Fsx.FsxVolumeSnapshotClassName,
 		},
@@ -193,8 +205,28 @@ func GetFsxPersistentVolumeClaimFromSnapshot(jira appv1.Jira, name string, names
 			DataSource: &corev1.TypedLocalObjectReference{
 				APIGroup: aws.String("snapshot.storage.k8s.io"),
 				Kind:     "VolumeSnapshot",
-				Name:     jira.Name + "-" + string(jira.UID),
+				Name:     naming.ClusterScoped(jira),
+			},
+		},
+	}
+	return pvc
+}
+
+func GetFsxPersistentVolumeClaim(jira appv1.Jira, name string, namespace string, size string) (pvc corev1.PersistentVolumeClaim) {
+	pvc = corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       namespace,
+			OwnerReferences: GetOwnerReferences(jira),
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany},
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: resource.MustParse(size + "Gi"),
+				},
 			},
+			StorageClassName: &jira.Spec.SharedFS.Fsx.FsxStorageClassName,
 		},
 	}
 	return pvc
@@ -27,6 +27,7 @@ import (
 	database "github.com/crossplane-contrib/provider-aws/apis/database/v1beta1"
 	efs "github.com/crossplane-contrib/provider-aws/apis/efs/v1alpha1"
 	rds "github.com/crossplane-contrib/provider-aws/apis/rds/v1alpha1"
+	route53 "github.com/crossplane-contrib/provider-aws/apis/route53/v1alpha1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -50,6 +51,7 @@ func init() {
 	utilruntime.Must(database.SchemeBuilder.AddToScheme(scheme))
 	utilruntime.Must(efs.AddToScheme(scheme))
 	utilruntime.Must(ec2.AddToScheme(scheme))
+	utilruntime.Must(route53.SchemeBuilder.AddToScheme(scheme))
 	utilruntime.Must(snapshot.AddToScheme(scheme))
 	utilruntime.Must(appv1.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
@@ -97,8 +99,10 @@ func main() {
 	}
 
 	if err = (&controllers.JiraReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:     mgr.GetClient(),
+		Scheme:     mgr.GetScheme(),
+		Recorder:   mgr.GetEventRecorderFor("jira-controller"),
+		RestConfig: mgr.GetConfig(),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Jira")
 		os.Exit(1)
@@ -0,0 +1,126 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const namespace = "jira_operator"
+
+// PhaseReady, PhaseStalled and PhasePaused complement step names as values of the phase gauge
+const (
+	PhaseReady   = "Ready"
+	PhaseStalled = "Stalled"
+	PhasePaused  = "Paused"
+)
+
+var (
+	timeToReady = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "time_to_ready_seconds",
+		Help:      "Time from Jira creation until all reconciliation steps were first ready.",
+	}, []string{"jira"})
+
+	stepDuration = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "step_duration_seconds",
+		Help:      "Time spent in a reconciliation step, so far if the step is not ready yet.",
+	}, []string{"jira", "step"})
+
+	stepStarted = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "step_started_timestamp_seconds",
+		Help:      "Time a reconciliation step which is not ready yet started, absent once the step is ready.",
+	}, []string{"jira", "step"})
+
+	stepCompletionDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "step_completion_duration_seconds",
+		Help:      "Time a reconciliation step took to become ready.",
+		Buckets:   []float64{10, 30, 60, 300, 600, 1200, 1800, 3600, 7200},
+	}, []string{"step"})
+
+	phase = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "phase",
+		Help:      "Current phase of a Jira, 1 for the step being reconciled, Ready, Stalled or Paused.",
+	}, []string{"jira", "phase"})
+
+	jobFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "job_failures_total",
+		Help:      "Number of failed jobs run for a Jira.",
+	}, []string{"jira", "job"})
+
+	rdsStatus = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "rds_status",
+		Help:      "Status of RDS instance of a Jira, 1 for the current status.",
+	}, []string{"jira", "instance", "status"})
+
+	efsStatus = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "efs_status",
+		Help:      "Lifecycle state of EFS of a Jira, 1 for the current state.",
+	}, []string{"jira", "instance", "status"})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(timeToReady, stepDuration, stepStarted, stepCompletionDuration, phase, jobFailures, rdsStatus, efsStatus)
+}
+
+func SetTimeToReady(jira string, seconds float64) {
+	timeToReady.WithLabelValues(jira).Set(seconds)
+}
+
+func SetStepDuration(jira string, step string, seconds float64) {
+	stepDuration.WithLabelValues(jira, step).Set(seconds)
+}
+
+// SetStepStarted records when a step which is not ready started, so that alerts can tell how long it has been in progress
+func SetStepStarted(jira string, step string, timestamp float64) {
+	stepStarted.WithLabelValues(jira, step).Set(timestamp)
+}
+
+// DeleteStepStarted removes the start time of a step once it is ready
+func DeleteStepStarted(jira string, step string) {
+	stepStarted.DeleteLabelValues(jira, step)
+}
+
+func ObserveStepCompletion(step string, seconds float64) {
+	stepCompletionDuration.WithLabelValues(step).Observe(seconds)
+}
+
+// SetPhase sets the phase of a Jira, a phase it was in previously is removed
+func SetPhase(jira string, currentPhase string) {
+	phase.DeletePartialMatch(prometheus.Labels{"jira": jira})
+	phase.WithLabelValues(jira, currentPhase).Set(1)
+}
+
+func IncJobFailures(jira string, job string) {
+	jobFailures.WithLabelValues(jira, job).Inc()
+}
+
+// SetRdsStatus sets the status of RDS instance, a status it was in previously is removed
+func SetRdsStatus(jira string, instance string, status string) {
+	rdsStatus.DeletePartialMatch(prometheus.Labels{"jira": jira})
+	rdsStatus.WithLabelValues(jira, instance, status).Set(1)
+}
+
+// SetEfsStatus sets the lifecycle state of EFS, a state it was in previously is removed
+func SetEfsStatus(jira string, instance string, status string) {
+	efsStatus.DeletePartialMatch(prometheus.Labels{"jira": jira})
+	efsStatus.WithLabelValues(jira, instance, status).Set(1)
+}
+
+// Delete removes all series of a deleted Jira
+func Delete(jira string) {
+	labels := prometheus.Labels{"jira": jira}
+	timeToReady.DeletePartialMatch(labels)
+	stepDuration.DeletePartialMatch(labels)
+	stepStarted.DeletePartialMatch(labels)
+	phase.DeletePartialMatch(labels)
+	jobFailures.DeletePartialMatch(labels)
+	rdsStatus.DeletePartialMatch(labels)
+	efsStatus.DeletePartialMatch(labels)
+}
@@ -0,0 +1,191 @@
+package naming
+
+import (
+	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+	"github.com/atlassian-labs/jira-operator/product"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// components the operator deploys next to Jira, used in app.kubernetes.io labels
+const (
+	ComponentDatabase         = "database"
+	ComponentSharedHome       = "shared-home"
+	ComponentNfsServer        = "nfs-server"
+	ComponentNfsInit          = "nfs-init"
+	ComponentNfsResize        = "nfs-resize"
+	ComponentLiquibase        = "liquibase"
+	ComponentResetRdsPassword = "reset-rds-credentials"
+	ComponentDelivery         = "delivery"
+)
+
+const (
+	LabelName      = "app.kubernetes.io/name"
+	LabelInstance  = "app.kubernetes.io/instance"
+	LabelComponent = "app.kubernetes.io/component"
+	LabelPartOf    = "app.kubernetes.io/part-of"
+	LabelManagedBy = "app.kubernetes.io/managed-by"
+	PartOf         = "jira"
+	ManagedBy      = "jira-operator"
+)
+
+// ClusterScoped returns the name of cluster scoped resources, such as crossplane managed resources and PVs,
+// which has to be unique across all Jira instances
+func ClusterScoped(jira appv1.Jira) string {
+	return jira.Name + "-" + string(jira.UID)
+}
+
+func MountTarget(jira appv1.Jira, index int) string {
+	return jira.Name + strconv.Itoa(index) + "-" + string(jira.UID)
+}
+
+// JiraStatefulSet returns the name of the StatefulSet the product Helm chart creates, which is the release name
+// if it already contains the chart name
+func JiraStatefulSet(jira appv1.Jira) string {
+	chart := product.Get(jira).Chart
+	if strings.Contains(jira.Name, chart) {
+		return jira.Name
+	}
+	return jira.Name + "-" + chart
+}
+
+// JiraIngress returns the name of the Ingress the product Helm chart creates, which is named like the StatefulSet
+func JiraIngress(jira appv1.Jira) string {
+	return JiraStatefulSet(jira)
+}
+
+// fixed names of children of Jiras created before names were derived from the Jira
+const (
+	LegacyDatabaseSecret                   = "jira-database-secret"
+	LegacyResetRdsPasswordServiceAccount   = "rds-reset-password-sa"
+	LegacySharedHomeClaim                  = "jira-shared-home"
+	LegacyNfsServerService                 = "nfs-server"
+	legacySharedHomePersistentVolumePrefix = "jira-shared-home-pv-"
+)
+
+// LegacySharedHomePersistentVolume returns the name shared home volume of Jiras created before names were derived
+// from the Jira. It contains the Jira UID, so it never is the name of the volume of another Jira
+func LegacySharedHomePersistentVolume(jira appv1.Jira) string {
+	return legacySharedHomePersistentVolumePrefix + string(jira.UID)
+}
+
+// isLegacy returns whether children of Jira keep the fixed names they were created with, see LegacyNamesAnnotation
+func isLegacy(jira appv1.Jira) bool {
+	return jira.Annotations[appv1.LegacyNamesAnnotation] == "true"
+}
+
+func DatabaseSecret(jira appv1.Jira) string {
+	if isLegacy(jira) {
+		return LegacyDatabaseSecret
+	}
+	return jira.Name + "-database-secret"
+}
+
+func RdsConnectionSecret(jira appv1.Jira) string {
+	return jira.Name + "-db-secret"
+}
+
+func EfsConnectionSecret(jira appv1.Jira) string {
+	return jira.Name + "-efs-secret"
+}
+
+func ResetRdsPasswordServiceAccount(jira appv1.Jira) string {
+	if isLegacy(jira) {
+		return LegacyResetRdsPasswordServiceAccount
+	}
+	return jira.Name + "-rds-reset-password"
+}
+
+func ResetRdsPasswordJob(jira appv1.Jira) string {
+	return jira.Name + "-reset-rds-credentials"
+}
+
+func LiquibaseJob(jira appv1.Jira) string {
+	return jira.Name + "-liquibase-changeset"
+}
+
+func LiquibaseChangelog(jira appv1.Jira) string {
+	return jira.Name + "-liquibase-changelog"
+}
+
+func LiquibaseSecret(jira appv1.Jira) string {
+	return jira.Name + "-liquibase-properties-secret"
+}
+
+func SharedHomeClaim(jira appv1.Jira) string {
+	if isLegacy(jira) {
+		return LegacySharedHomeClaim
+	}
+	return jira.Name + "-shared-home"
+}
+
+func SharedHomePersistentVolume(jira appv1.Jira) string {
+	if isLegacy(jira) {
+		return LegacySharedHomePersistentVolume(jira)
+	}
+	return jira.Name + "-shared-home-" + string(jira.UID)
+}
+
+func NfsServer(jira appv1.Jira) string {
+	return jira.Name + "-nfs-server"
+}
+
+// NfsServerService returns the governing service of nfs server StatefulSet, which cannot be changed once it is created
+func NfsServerService(jira appv1.Jira) string {
+	if isLegacy(jira) {
+		return LegacyNfsServerService
+	}
+	return NfsServer(jira)
+}
+
+// NfsServerSelectorLabels returns the labels selecting nfs server pods. StatefulSet selectors are immutable,
+// so Jiras with legacy names keep selecting them by app label
+func NfsServerSelectorLabels(jira appv1.Jira) map[string]string {
+	if isLegacy(jira) {
+		return map[string]string{"app": "nfs-server"}
+	}
+	return SelectorLabels(jira, ComponentNfsServer)
+}
+
+func NfsInitJob(jira appv1.Jira) string {
+	return jira.Name + "-nfs-init"
+}
+
+// NfsResizeJob returns the name of the job growing the filesystem exported by nfs server to a size, one per resize
+func NfsResizeJob(jira appv1.Jira, size int64) string {
+	return jira.Name + "-nfs-resize-" + strconv.FormatInt(size, 10)
+}
+
+func ResourceQuota(jira appv1.Jira) string {
+	return jira.Name + "-quota"
+}
+
+func LimitRange(jira appv1.Jira) string {
+	return jira.Name + "-limits"
+}
+
+// NextBlueGreenStack returns the name of the Jira blue/green deployment deploys a new release to. It is suffixed with
+// the time the deployment started, so that it never is the name of a stack still being deleted, or of one whose
+// resources were retained on delete
+func NextBlueGreenStack(jira appv1.Jira, startedAt time.Time) string {
+	return jira.Name + "-" + strconv.FormatInt(startedAt.Unix(), 36)
+}
+
+// Labels returns standard labels of a component deployed for Jira
+func Labels(jira appv1.Jira, component string) map[string]string {
+	labels := SelectorLabels(jira, component)
+	labels[LabelComponent] = component
+	labels[LabelPartOf] = PartOf
+	labels[LabelManagedBy] = ManagedBy
+	return labels
+}
+
+// SelectorLabels returns labels which select pods of a component deployed for one Jira only,
+// so that several Jira instances can share a namespace
+func SelectorLabels(jira appv1.Jira, component string) map[string]string {
+	return map[string]string{
+		LabelName:     component,
+		LabelInstance: jira.Name,
+	}
+}
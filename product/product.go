@@ -0,0 +1,93 @@
+package product
+
+import (
+	"fmt"
+	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+)
+
+// Product is an Atlassian data center product deployed from https://atlassian.github.io/data-center-helm-charts
+type Product struct {
+	// Chart is the name of the product Helm chart, which is also the key of product settings in chart values,
+	// the name of the product container and the app.kubernetes.io/name label of product pods
+	Chart string
+	// Image overrides the image repository of the chart, for products sharing a chart with another one
+	Image string
+	// Database, DatabaseUser and DatabaseReadOnlyUser are created in RDS by liquibase migrations
+	Database             string
+	DatabaseUser         string
+	DatabaseReadOnlyUser string
+	HealthCheckPath      string
+	// databaseValues returns database Helm values of the product other than url and credentials, for an RDS engine
+	databaseValues func(engine string) map[string]interface{}
+}
+
+var jira = Product{
+	Chart:                "jira",
+	Database:             "jira",
+	DatabaseUser:         "jira",
+	DatabaseReadOnlyUser: "jira-ro",
+	HealthCheckPath:      "/status",
+	databaseValues: func(engine string) map[string]interface{} {
+		if engine == "aurora-postgresql" {
+			return map[string]interface{}{"type": "postgresaurora96"}
+		}
+		return map[string]interface{}{"type": "postgres72"}
+	},
+}
+
+var products = map[string]Product{
+	appv1.ProductJira: jira,
+	// Jira Service Management is Jira chart running JSM image
+	appv1.ProductJSM: {
+		Chart:                jira.Chart,
+		Image:                "atlassian/jira-servicemanagement",
+		Database:             jira.Database,
+		DatabaseUser:         jira.DatabaseUser,
+		DatabaseReadOnlyUser: jira.DatabaseReadOnlyUser,
+		HealthCheckPath:      jira.HealthCheckPath,
+		databaseValues:       jira.databaseValues,
+	},
+	appv1.ProductConfluence: {
+		Chart:                "confluence",
+		Database:             "confluence",
+		DatabaseUser:         "confluence",
+		DatabaseReadOnlyUser: "confluence-ro",
+		HealthCheckPath:      "/status",
+		databaseValues: func(engine string) map[string]interface{} {
+			return map[string]interface{}{"type": "postgresql"}
+		},
+	},
+	appv1.ProductBitbucket: {
+		Chart:                "bitbucket",
+		Database:             "bitbucket",
+		DatabaseUser:         "bitbucket",
+		DatabaseReadOnlyUser: "bitbucket-ro",
+		HealthCheckPath:      "/status",
+		databaseValues: func(engine string) map[string]interface{} {
+			return map[string]interface{}{"driver": "org.postgresql.Driver"}
+		},
+	},
+}
+
+// Get returns the product deployed by Jira custom resource, which is Jira when none is set.
+// An unknown product is refused by the CRD and by Validate before anything is deployed from it
+func Get(jira appv1.Jira) Product {
+	if p, ok := products[jira.Spec.Product]; ok {
+		return p
+	}
+	return products[appv1.ProductJira]
+}
+
+// Validate returns an error when Jira custom resource sets a product which is not supported
+func Validate(jira appv1.Jira) error {
+	if _, ok := products[jira.Spec.Product]; !ok && jira.Spec.Product != "" {
+		return fmt.Errorf("unsupported spec.product %s, it must be one of %s, %s, %s or %s", jira.Spec.Product,
+			appv1.ProductJira, appv1.ProductJSM, appv1.ProductConfluence, appv1.ProductBitbucket)
+	}
+	return nil
+}
+
+// DatabaseValues returns database Helm values of the product other than url and credentials
+func (p Product) DatabaseValues(engine string) map[string]interface{} {
+	return p.databaseValues(engine)
+}
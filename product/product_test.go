@@ -0,0 +1,39 @@
+package product
+
+import (
+	"testing"
+
+	appv1 "github.com/atlassian-labs/jira-operator/api/v1"
+)
+
+func TestGet(t *testing.T) {
+	tests := []struct {
+		product   string
+		wantChart string
+		wantImage string
+		wantUser  string
+		wantErr   bool
+	}{
+		{"", "jira", "", "jira", false},
+		{appv1.ProductJira, "jira", "", "jira", false},
+		{appv1.ProductJSM, "jira", "atlassian/jira-servicemanagement", "jira", false},
+		{appv1.ProductConfluence, "confluence", "", "confluence", false},
+		{appv1.ProductBitbucket, "bitbucket", "", "bitbucket", false},
+		{"bamboo", "jira", "", "jira", true},
+	}
+	for _, test := range tests {
+		t.Run(test.product, func(t *testing.T) {
+			jira := appv1.Jira{Spec: appv1.JiraSpec{Product: test.product}}
+			p := Get(jira)
+			if p.Chart != test.wantChart || p.Image != test.wantImage || p.DatabaseUser != test.wantUser {
+				t.Errorf("Get() = %+v, want chart %s, image %q and database user %s", p, test.wantChart, test.wantImage, test.wantUser)
+			}
+			if p.DatabaseValues("postgres") == nil {
+				t.Error("product has no database values")
+			}
+			if err := Validate(jira); (err != nil) != test.wantErr {
+				t.Errorf("Validate() error = %v, want error %t", err, test.wantErr)
+			}
+		})
+	}
+}